@@ -1,5 +1,8 @@
 
 import Foundation
+#if canImport(UIKit)
+import UIKit
+#endif
 
 // Do _not_ modify
 // https://docs.google.com/spreadsheets/d/1upHiDHWu5m30tYdhMDP4GXheOWUE4r3VrHfmAUXiuyI#gid=0
@@ -8,25 +11,41 @@ import Foundation
 // swiftlint:disable line_length
 public struct Strings {
     public struct Greeting {
-        static let GreetingHelloWorld = Strings.localized("greeting_hello_world", value: "Hello, world!", comment: "Default greeting.")
-        static let GreetingText = Strings.localized("greeting_text", value: "Isn't this a nice iOS device?", comment: "Overridden per platform")
+        public static let GreetingHelloWorld = Strings.localized("greeting_hello_world", tableName: "LocalizableGen", value: "Hello, world!", comment: "Default greeting.")
+        public static let GreetingText = Strings.localized("greeting_text", tableName: "LocalizableGen", value: "Isn't this a nice iOS device?", comment: "Overridden per platform")
     }
     
     public struct SongLine {
-        static let SongLineBottlesOfBeerPlOne = Strings.localized("song_line__bottles_of_beer__pl_one", value: "%1$d bottle of beer on the wall, %1$d bottle of beer.")
-        static let SongLineBottlesOfBeerPlOther = Strings.localized("song_line__bottles_of_beer__pl_other", value: "%1$d bottles of beer on the wall, %1$d bottles of beer.")
+        public static func SongLineBottlesOfBeer(_ count: Int) -> String {
+            return String.localizedStringWithFormat(Strings.localized("song_line__bottles_of_beer", tableName: "LocalizableGen", value: "%1$d bottles of beer on the wall, %1$d bottles of beer."), count)
+        }
     }
     
     public struct WeirdCharacters {
-        static let WeirdCharactersExample1 = Strings.localized("weird_characters__example_1", value: "Rock &#39;n&#39; Roll")
-        static let WeirdCharactersExample2 = Strings.localized("weird_characters__example_2", value: "Questions &amp; Answers")
-        static let WeirdCharactersExample3 = Strings.localized("weird_characters__example_3", value: "What&#39;s \&#34;This\&#34;")
-        static let WeirdCharactersExample4 = Strings.localized("weird_characters__example_4", value: "Some %1$@ iOS style string, %@ or %2$@")
-        static let WeirdCharactersExample5 = Strings.localized("weird_characters__example_5", value: "Some &lt;a href=\&#34;http://www.google.com\&#34;&gt;Link&lt;/a&gt;")
+        public static let WeirdCharactersExample1 = Strings.localized("weird_characters__example_1", tableName: "LocalizableGen", value: "Rock 'n' Roll")
+        public static let WeirdCharactersExample2 = Strings.localized("weird_characters__example_2", tableName: "LocalizableGen", value: "Questions & Answers")
+        public static let WeirdCharactersExample3 = Strings.localized("weird_characters__example_3", tableName: "LocalizableGen", value: "What's \"This\"")
+        public static func WeirdCharactersExample4(_ p1: String, _ p2: String) -> String {
+            return String(format: Strings.localized("weird_characters__example_4", tableName: "LocalizableGen", value: "Some %1$@ iOS style string, %@ or %2$@"), p1, p2)
+        }
+        public static let WeirdCharactersExample5 = Strings.localized("weird_characters__example_5", tableName: "LocalizableGen", value: "Some <a href=\"http://www.google.com\">Link</a>")
+        public static var WeirdCharactersExample5Attributed: NSAttributedString { return Strings.attributed(WeirdCharactersExample5) }
     }
     
 
     public static func localized(_ key: String, tableName: String? = nil, bundle: Bundle = Bundle.main, value: String, comment: String = "") -> String {
         return NSLocalizedString(key, tableName: tableName, bundle: bundle, value: value, comment: comment)
     }
+
+    public static func attributed(_ html: String) -> NSAttributedString {
+        let options: [NSAttributedString.DocumentReadingOptionKey: Any] = [
+            .documentType: NSAttributedString.DocumentType.html,
+            .characterEncoding: String.Encoding.utf8.rawValue
+        ]
+        guard let data = html.data(using: .utf8),
+            let attributed = try? NSAttributedString(data: data, options: options, documentAttributes: nil) else {
+            return NSAttributedString(string: html)
+        }
+        return attributed
+    }
 }
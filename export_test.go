@@ -0,0 +1,164 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bleeding182/localization/writer"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+// parseArgs parses the command line like main. The flags are registered on a new app every time, since kingpin keeps
+// the values of flags without a default from earlier calls.
+func parseArgs(t *testing.T, args ...string) string {
+	app = kingpin.New("localization", appDescription)
+	sheetID = app.Flag("sheetID", "").String()
+	keyPattern = app.Flag("keyPattern", "").Default("default").String()
+	localeAliases = app.Flag("localeAlias", "").StringMap()
+	includeSheets = app.Flag("sheet", "").Strings()
+	excludeSheets = app.Flag("excludeSheet", "").Strings()
+	includeHidden = app.Flag("includeHidden", "").Bool()
+	ignorePattern = app.Flag("ignorePattern", "").Default("^_").Regexp()
+	sheetRangeLimit = app.Flag("range", "").String()
+	gridData = app.Flag("gridData", "").Bool()
+	excludeFormat = app.Flag("excludeFormat", "").Default("strikethrough").String()
+	glossaryTitle = app.Flag("glossary", "").Default("glossary").String()
+	RegisterCommands(app)
+	RegisterImportCommands(app)
+	RegisterPushCommand(app)
+	RegisterLintCommand(app)
+	command, err := app.Parse(append([]string{"--sheetID", "abc"}, args...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.SetKeyPattern(*keyPattern); err != nil {
+		t.Fatal(err)
+	}
+	return command
+}
+
+func row(cells ...string) []interface{} {
+	values := make([]interface{}, len(cells))
+	for i, cell := range cells {
+		values[i] = cell
+	}
+	return values
+}
+
+// export runs the export of the command into a temporary folder and returns the content of all files by their relative path.
+// {folder} in the arguments is replaced with the temporary folder.
+func export(t *testing.T, entrySets []*EntrySet, args ...string) map[string]string {
+	folder, err := ioutil.TempDir("", "localization")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	for i, arg := range args {
+		args[i] = strings.Replace(arg, "{folder}", folder, -1)
+	}
+	command := parseArgs(t, append(args, "--outputFolder", folder)...)
+
+	wg, locales := Export(command, "abc", entrySets)
+	wg.Wait()
+	if finisher, ok := Writers[command].(writer.Finisher); ok {
+		finisher.Finish(locales)
+	}
+
+	files := make(map[string]string)
+	err = filepath.Walk(folder, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := ioutil.ReadFile(file)
+		relative, _ := filepath.Rel(folder, file)
+		files[filepath.ToSlash(relative)] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func dottedEntrySets() []*EntrySet {
+	header := row("key", "value")
+	return []*EntrySet{
+		{GID: "0", Locale: "default", Headers: header, Values: [][]interface{}{
+			row("home.greeting", "Hello %1$@"),
+			row("home.title", "Home"),
+			row("cart.checkout.pay", "Pay"),
+			row("cart.items#one", "%d item"),
+			row("cart.items#other", "%d items"),
+		}},
+		{GID: "1", Locale: "de", Headers: header, Values: [][]interface{}{
+			row("home.greeting", "Hallo %1$@"),
+			row("cart.items#one", "%d Artikel"),
+			row("cart.items#other", "%d Artikel"),
+		}},
+	}
+}
+
+func TestExportDottedKeys(t *testing.T) {
+	defer writer.SetKeyPattern("default")
+
+	tests := map[string]map[string][]string{
+		"android": {
+			"values/generated_strings.xml": {
+				`<string name="home.greeting">Hello %1$s</string>`,
+				`<string name="cart_items__pl_one">%d item</string>`,
+				`<plurals name="cart.items">`,
+				`<item quantity="other">@string/cart_items__pl_other</item>`,
+			},
+			"values-de/generated_strings.xml": {`<string name="cart_items__pl_other">%d Artikel</string>`},
+			"kotlin/Strings.kt": {
+				"context.getString(R.string.home_greeting, p1)",
+				"context.getString(R.string.cart_checkout_pay)",
+				"getQuantityString(R.plurals.cart_items, count, count)",
+			},
+		},
+		"ios": {
+			"Base.lproj/LocalizableGen.strings":   {`"home.greeting" = "Hello %1$@";`},
+			"de.lproj/LocalizableGen.stringsdict": {"<key>cart.items</key>", "<string>%d Artikel</string>"},
+			"swift/Strings.swift": {
+				`static func HomeGreeting(_ p1: String) -> String`,
+				`static func CartItems(_ count: Int) -> String`,
+			},
+		},
+		"compose": {
+			"values/strings.xml": {
+				`<string name="home_greeting">Hello %1$s</string>`,
+				`<plurals name="cart_items">`,
+			},
+			"values-de/strings.xml": {`<item quantity="other">%1$d Artikel</item>`},
+		},
+		"moko": {
+			"MR/base/strings.xml": {`<string name="home_greeting">Hello %1$s</string>`},
+			"MR/base/plurals.xml": {`<plural name="cart_items">`, `<item quantity="one">%d item</item>`},
+			"MR/de/plurals.xml":   {`<item quantity="other">%d Artikel</item>`},
+		},
+	}
+	generated := map[string][]string{
+		"android": {"--kotlinOutputFolder", "{folder}/kotlin", "--kotlinPackage", "com.example"},
+		"ios":     {"--utilOutputFolder", "{folder}/swift"},
+	}
+	for command, expected := range tests {
+		files := export(t, dottedEntrySets(), append([]string{"--keyPattern", "dotted", command}, generated[command]...)...)
+		for file, snippets := range expected {
+			content, ok := files[file]
+			if !ok {
+				t.Errorf("%v: missing %v", command, file)
+				continue
+			}
+			for _, snippet := range snippets {
+				if !strings.Contains(content, snippet) {
+					t.Errorf("%v: %v should contain %q\n%v", command, file, snippet, content)
+				}
+			}
+		}
+	}
+}
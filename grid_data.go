@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/api/sheets/v4"
+)
+
+// gridFields limits the grid data to what's needed for the values, notes and the --excludeFormat
+const gridFields = "sheets(properties(sheetId,title),data(rowData(values(formattedValue,note,effectiveFormat(textFormat(strikethrough),backgroundColor)))))"
+
+// excludeColor is the background color of --excludeFormat, if it is one
+var excludeColor string
+
+var hexColor = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+func parseExcludeFormat() error {
+	format := strings.ToLower(*excludeFormat)
+	*excludeFormat = format
+	excludeColor = ""
+	switch {
+	case format == "strikethrough" || format == "none":
+	case hexColor.MatchString(format):
+		excludeColor = format
+	default:
+		return fmt.Errorf("--excludeFormat must be strikethrough, none or a background color like #ff0000, not %q", *excludeFormat)
+	}
+	return nil
+}
+
+// readGridData reads the formatted values of the tabs along with the notes and formatting of every cell
+func readGridData(ctx context.Context, srv *sheets.Service, tabs []*sheets.SheetProperties) ([]*sheetData, error) {
+	ranges := make([]string, len(tabs))
+	for i, tab := range tabs {
+		ranges[i] = sheetRange(tab.Title)
+	}
+	res, err := srv.Spreadsheets.Get(*sheetID).Ranges(ranges...).IncludeGridData(true).Fields(gridFields).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]*sheetData, len(tabs))
+	for i, tab := range tabs {
+		for _, sheet := range res.Sheets {
+			if sheet.Properties.SheetId == tab.SheetId {
+				data[i] = newGridSheetData(sheet)
+			}
+		}
+		if data[i] == nil {
+			return nil, fmt.Errorf("no grid data for sheet %q", tab.Title)
+		}
+	}
+	return data, nil
+}
+
+// newGridSheetData converts the grid data. A row is excluded if the cell of its key has the --excludeFormat.
+func newGridSheetData(sheet *sheets.Sheet) *sheetData {
+	data := &sheetData{}
+	keyIndex := -1
+	for _, grid := range sheet.Data {
+		for _, row := range grid.RowData {
+			values := make([]interface{}, len(row.Values))
+			var notes []string
+			for i, cell := range row.Values {
+				values[i] = cell.FormattedValue
+				if keyIndex < 0 && len(data.values) == 0 && cell.FormattedValue == *keyColumnName {
+					keyIndex = i
+				}
+				if cell.Note != "" {
+					notes = append(notes, cell.Note)
+				}
+			}
+
+			excluded := keyIndex >= 0 && keyIndex < len(row.Values) && hasExcludeFormat(row.Values[keyIndex])
+			data.values = append(data.values, values)
+			data.notes = append(data.notes, notes)
+			data.excluded = append(data.excluded, excluded && len(data.values) > 1)
+		}
+	}
+	return data
+}
+
+func hasExcludeFormat(cell *sheets.CellData) bool {
+	format := cell.EffectiveFormat
+	if format == nil {
+		return false
+	}
+	switch {
+	case *excludeFormat == "strikethrough":
+		return format.TextFormat != nil && format.TextFormat.Strikethrough
+	case excludeColor != "":
+		return format.BackgroundColor != nil && colorHex(format.BackgroundColor) == excludeColor
+	}
+	return false
+}
+
+// colorHex returns the color as #rrggbb
+func colorHex(color *sheets.Color) string {
+	component := func(c float64) int {
+		return int(math.Round(c * 255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", component(color.Red), component(color.Green), component(color.Blue))
+}
+
+// noteComment adds the notes of a row to its comment, each on a single line
+func noteComment(comment string, notes []string) string {
+	lines := make([]string, 0, len(notes)+1)
+	if comment != "" {
+		lines = append(lines, comment)
+	}
+	for _, note := range notes {
+		lines = append(lines, strings.Join(strings.Fields(note), " "))
+	}
+	return strings.Join(lines, " ")
+}
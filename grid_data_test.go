@@ -0,0 +1,183 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestParseExcludeFormat(t *testing.T) {
+	tests := map[string]string{
+		"strikethrough": "",
+		"None":          "",
+		"#FF0000":       "#ff0000",
+	}
+	for format, color := range tests {
+		parseArgs(t, "android", "--excludeFormat", format)
+		if err := parseExcludeFormat(); err != nil {
+			t.Errorf("%v: %v", format, err)
+		}
+		if excludeColor != color {
+			t.Errorf("%v: expected the color %q, got %q", format, color, excludeColor)
+		}
+	}
+
+	for _, format := range []string{"bold", "#f00", "red"} {
+		parseArgs(t, "android", "--excludeFormat", format)
+		if err := parseExcludeFormat(); err == nil {
+			t.Errorf("%v should be an invalid --excludeFormat", format)
+		}
+	}
+}
+
+func TestColorHex(t *testing.T) {
+	tests := map[string]*sheets.Color{
+		"#000000": {},
+		"#ff0000": {Red: 1},
+		"#ffff00": {Red: 1, Green: 1},
+		"#80bfff": {Red: 0.5, Green: 0.75, Blue: 1},
+	}
+	for hex, color := range tests {
+		if got := colorHex(color); got != hex {
+			t.Errorf("colorHex(%+v) = %v, want %v", color, got, hex)
+		}
+	}
+}
+
+// cell returns a cell with the value and formatting of a sheet
+func cell(value string, strikethrough bool, background *sheets.Color) *sheets.CellData {
+	return &sheets.CellData{
+		FormattedValue: value,
+		EffectiveFormat: &sheets.CellFormat{
+			TextFormat:      &sheets.TextFormat{Strikethrough: strikethrough},
+			BackgroundColor: background,
+		},
+	}
+}
+
+func TestHasExcludeFormat(t *testing.T) {
+	red := &sheets.Color{Red: 1}
+	tests := []struct {
+		format string
+		cell   *sheets.CellData
+		want   bool
+	}{
+		{"strikethrough", cell("key", true, nil), true},
+		{"strikethrough", cell("key", false, red), false},
+		{"strikethrough", &sheets.CellData{FormattedValue: "key"}, false},
+		{"#ff0000", cell("key", false, red), true},
+		{"#ff0000", cell("key", true, &sheets.Color{Green: 1}), false},
+		{"#ff0000", cell("key", false, nil), false},
+		{"none", cell("key", true, red), false},
+	}
+	for _, test := range tests {
+		parseArgs(t, "android", "--excludeFormat", test.format)
+		if err := parseExcludeFormat(); err != nil {
+			t.Fatal(err)
+		}
+		if got := hasExcludeFormat(test.cell); got != test.want {
+			t.Errorf("%v: hasExcludeFormat(%+v) = %v, want %v", test.format, test.cell.EffectiveFormat.TextFormat, got, test.want)
+		}
+	}
+}
+
+func TestNewGridSheetData(t *testing.T) {
+	parseArgs(t, "android")
+	if err := parseExcludeFormat(); err != nil {
+		t.Fatal(err)
+	}
+
+	header := cell("key", true, nil) // a struck through header is never excluded
+	header.Note = "The keys"
+	sheet := &sheets.Sheet{Data: []*sheets.GridData{{RowData: []*sheets.RowData{
+		{Values: []*sheets.CellData{cell("comment", false, nil), header, cell("value", false, nil)}},
+		{Values: []*sheets.CellData{cell("", false, nil), cell("greeting_hello", false, nil), {FormattedValue: "Hello", Note: "Shown\n  on start"}}},
+		{Values: []*sheets.CellData{cell("", false, nil), cell("greeting_old", true, nil), cell("Old", false, nil)}},
+		{Values: []*sheets.CellData{cell("", true, nil)}},
+	}}}}
+
+	data := newGridSheetData(sheet)
+	values := [][]interface{}{row("comment", "key", "value"), row("", "greeting_hello", "Hello"), row("", "greeting_old", "Old"), row("")}
+	if !reflect.DeepEqual(data.values, values) {
+		t.Errorf("got values %v, want %v", data.values, values)
+	}
+	notes := [][]string{{"The keys"}, {"Shown\n  on start"}, nil, nil}
+	if !reflect.DeepEqual(data.notes, notes) {
+		t.Errorf("got notes %q, want %q", data.notes, notes)
+	}
+	excluded := []bool{false, false, true, false}
+	if !reflect.DeepEqual(data.excluded, excluded) {
+		t.Errorf("got excluded %v, want %v", data.excluded, excluded)
+	}
+}
+
+func TestNoteComment(t *testing.T) {
+	tests := []struct {
+		comment string
+		notes   []string
+		want    string
+	}{
+		{"", nil, ""},
+		{"Greeting", nil, "Greeting"},
+		{"", []string{"Max. 20\ncharacters"}, "Max. 20 characters"},
+		{"Greeting", []string{"Shown  on start", "Formal"}, "Greeting Shown on start Formal"},
+	}
+	for _, test := range tests {
+		if got := noteComment(test.comment, test.notes); got != test.want {
+			t.Errorf("noteComment(%q, %q) = %q, want %q", test.comment, test.notes, got, test.want)
+		}
+	}
+}
+
+func TestRemoveExcluded(t *testing.T) {
+	parseArgs(t, "android")
+	parse := func(title string, excluded []bool, notes [][]string, rows ...[]interface{}) *sheet {
+		locale, err := parseLocale(title)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sheets := make(chan *sheet, 1)
+		parseEntrySetToSheet(&EntrySet{Locale: title, Headers: row("key", "value", "comment"), Values: rows, Notes: notes, Excluded: excluded}, locale, sheets)
+		return <-sheets
+	}
+
+	base := parse("default", []bool{false, true, false, true}, [][]string{{"Shown on start"}},
+		row("greeting_hello", "Hello", "Greeting"),
+		row("greeting_old", "Old"),
+		row("cart_items__pl_one", "%d item"),
+		row("cart_items__pl_other", "%d items"),
+	)
+	de := parse("de", []bool{false, false, true},
+		nil,
+		row("greeting_hello", "Hallo"),
+		row("greeting_old", "Alt"),
+		row("greeting_bye", "Tschüss"),
+	)
+	removeExcluded([]*sheet{base, de})
+
+	keys := func(sheet *sheet) []string {
+		var keys []string
+		for _, ls := range sheet.Data {
+			keys = append(keys, ls.Key.Original())
+		}
+		sort.Strings(keys)
+		return keys
+	}
+	if got := keys(base); !reflect.DeepEqual(got, []string{"cart_items__pl_one", "greeting_hello"}) {
+		t.Errorf("expected greeting_hello and the one quantity of cart_items, got %v", got)
+	}
+	if plural := base.Plurals["cart_items"]; len(plural.Values) != 1 {
+		t.Errorf("expected only the one quantity of cart_items, got %+v", plural.Values)
+	}
+	for _, ls := range base.Data {
+		if ls.Key.Original() == "greeting_hello" && ls.Comment != "Greeting Shown on start" {
+			t.Errorf("expected the note in the comment, got %q", ls.Comment)
+		}
+	}
+	// greeting_old is excluded by the default sheet, greeting_bye by its own row
+	if got := keys(de); !reflect.DeepEqual(got, []string{"greeting_hello"}) {
+		t.Errorf("expected only greeting_hello in de, got %v", got)
+	}
+}
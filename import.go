@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/csv"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/bleeding182/localization/importer"
+	"github.com/bleeding182/localization/writer"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const importCommand = "import"
+
+var importFolder *string
+
+var Importers = map[string]importer.Importer{
+	"android": importer.AndroidImporter{},
+	"ios":     importer.IOSImporter{},
+}
+
+func RegisterImportCommands(app *kingpin.Application) {
+	command := app.Command(importCommand, "Import the strings of an existing project into one CSV file per locale, using the columns expected by the export. The files can then be imported into the sheets of your spreadsheet.")
+	importFolder = command.Flag("outputFolder", "Set the output directory of the CSV files.").Default("imports").String()
+	for _, importer := range Importers {
+		importer.RegisterCommand(command)
+	}
+}
+
+// isImport returns true if the parsed command is one of the import subcommands, e.g. `import android`
+func isImport(command string) bool {
+	return strings.HasPrefix(command, importCommand+" ")
+}
+
+// Import runs the importer of the command and writes the CSV files
+func Import(command string) {
+	sheets, err := Importers[strings.TrimPrefix(command, importCommand+" ")].Import()
+	if err != nil {
+		log.Fatalf("Unable to import strings. %v", err)
+	}
+	if len(sheets) == 0 {
+		log.Fatal("No strings found")
+	}
+
+	os.MkdirAll(*importFolder, os.ModePerm)
+	for _, sheet := range sheets {
+		for _, row := range sheet.Rows {
+			if _, err := writer.ParseKey(row.Key); err != nil {
+				log.Printf("Warning: %v of %v will be skipped by the export, use --keyPattern or rename it", err, sheet.Locale)
+			}
+		}
+
+		filename := path.Join(*importFolder, sheet.Locale.String()+".csv")
+		if err := writeCSV(filename, sheet); err != nil {
+			log.Fatalf("Unable to write %v. %v", filename, err)
+		}
+		log.Printf("Imported %d strings into %v", len(sheet.Rows), filename)
+	}
+}
+
+func writeCSV(filename string, sheet *importer.Sheet) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write(csvHeader())
+	for _, row := range sheet.Rows {
+		w.Write(csvRecord(row))
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvHeader uses the column names of the export
+func csvHeader() []string {
+	return []string{*keyColumnName, *valueColumnName, *commentColumnName, *htmlColumnName, *translatableColumnName, *moduleColumnName}
+}
+
+func csvRecord(row importer.Row) []string {
+	var html, translatable string
+	if row.HTML {
+		html = "true"
+	} else if writer.HasMarkup(row.Value) {
+		// would be detected as html otherwise
+		html = "false"
+	}
+	if !row.Translatable {
+		translatable = "false"
+	}
+	return []string{row.Key, row.Value, row.Comment, html, translatable, row.Module}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/bleeding182/localization/importer"
+)
+
+func importSheets(t *testing.T, args ...string) []*importer.Sheet {
+	command := parseArgs(t, append([]string{"import"}, args...)...)
+	sheets, err := Importers[args[0]].Import()
+	if err != nil {
+		t.Fatalf("%v: %v", command, err)
+	}
+	return sheets
+}
+
+// entrySets converts the imported sheets like a spreadsheet the CSV files were imported into
+func entrySets(sheets []*importer.Sheet) []*EntrySet {
+	var entrySets []*EntrySet
+	for i, sheet := range sheets {
+		entrySet := &EntrySet{GID: strconv.Itoa(i), Locale: sheet.Locale.Name, Headers: row(csvHeader()...)}
+		for _, r := range sheet.Rows {
+			entrySet.Values = append(entrySet.Values, row(csvRecord(r)...))
+		}
+		entrySets = append(entrySets, entrySet)
+	}
+	return entrySets
+}
+
+// rowsByKey ignores the order, since the export sorts the strings by key
+func rowsByKey(sheets []*importer.Sheet) map[string]importer.Row {
+	rows := make(map[string]importer.Row)
+	for _, sheet := range sheets {
+		for _, row := range sheet.Rows {
+			rows[sheet.Locale.Name+" "+row.Key] = row
+		}
+	}
+	return rows
+}
+
+func TestImportAndroidRoundTrip(t *testing.T) {
+	imported := importSheets(t, "android", "importer/testdata/android")
+	files := export(t, entrySets(imported), "android")
+
+	folder, err := ioutil.TempDir("", "localization")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+	for name, content := range files {
+		if filepath.Ext(name) != ".xml" {
+			continue
+		}
+		file := filepath.Join(folder, "res", filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), os.ModePerm)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exported := rowsByKey(importSheets(t, "android", filepath.Join(folder, "res")))
+	for key, row := range rowsByKey(imported) {
+		if exported[key] != row {
+			t.Errorf("%v changed after exporting it: got %+v, want %+v", key, exported[key], row)
+		}
+	}
+	if len(exported) != len(rowsByKey(imported)) {
+		t.Errorf("expected the same strings, got %v", exported)
+	}
+}
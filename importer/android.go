@@ -0,0 +1,248 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bleeding182/localization/writer"
+	"github.com/bleeding182/localization/writer/android"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const tagAndroid = "android"
+
+var resFolder *string
+
+type AndroidImporter struct{}
+
+func (importer AndroidImporter) Tag() string {
+	return tagAndroid
+}
+
+func (importer AndroidImporter) RegisterCommand(command *kingpin.CmdClause) {
+	subcommand := command.Command(tagAndroid, "Import the strings, plurals and string-arrays of all values*/*.xml files. Plurals will use `__pl_<quantity>` keys and string-arrays `<name>__<index>`.")
+	resFolder = subcommand.Arg("res", "The res directory containing the values* folders.").Required().ExistingDir()
+}
+
+// resource is a string, plurals or string-array element
+type resource struct {
+	Name         string `xml:"name,attr"`
+	Translatable string `xml:"translatable,attr"`
+	Value        string `xml:",innerxml"`
+	Items        []struct {
+		Quantity string `xml:"quantity,attr"`
+		Value    string `xml:",innerxml"`
+	} `xml:"item"`
+}
+
+func (importer AndroidImporter) Import() ([]*Sheet, error) {
+	folders, err := ioutil.ReadDir(*resFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	var sheets []*Sheet
+	for _, folder := range folders {
+		if !folder.IsDir() || (folder.Name() != "values" && !strings.HasPrefix(folder.Name(), "values-")) {
+			continue
+		}
+		rows, err := importValues(filepath.Join(*resFolder, folder.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		locale, err := writer.ParseQualifier(strings.TrimPrefix(strings.TrimPrefix(folder.Name(), "values"), "-"))
+		if err != nil {
+			log.Printf("Warning: skipping %v: %v", folder.Name(), err)
+			continue
+		}
+		sheetOf(&sheets, locale).add(rows...)
+	}
+	return sheets, nil
+}
+
+// importValues reads all xml files of the folder. Plural items referencing other strings are resolved once all files were read.
+func importValues(folder string) ([]Row, error) {
+	files, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	references := make(map[int]string)
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".xml" {
+			continue
+		}
+		if err := importResources(filepath.Join(folder, file.Name()), &rows, references); err != nil {
+			return nil, err
+		}
+	}
+
+	values := make(map[string]Row)
+	for _, row := range rows {
+		values[row.Key] = row
+	}
+	for i, name := range references {
+		referenced, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("%v: plural %q references the unknown string %q", folder, rows[i].Key, name)
+		}
+		rows[i].Value, rows[i].HTML = referenced.Value, referenced.HTML
+	}
+	return rows, nil
+}
+
+var stringReference = regexp.MustCompile(`^@string/(\S+)$`)
+
+// importResources adds the rows of the file. Comments are added to the following element, except for the regions of the generated files.
+func importResources(file string, rows *[]Row, references map[int]string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := xml.NewDecoder(f)
+	depth := 0
+	comment := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %v", file, err)
+		}
+
+		switch t := token.(type) {
+		case xml.Comment:
+			text := strings.TrimSpace(string(t))
+			if depth == 1 && !strings.HasPrefix(text, "region") && !strings.HasPrefix(text, "endregion") {
+				comment = text
+			}
+		case xml.EndElement:
+			depth--
+		case xml.StartElement:
+			if depth == 0 {
+				if t.Name.Local != "resources" {
+					return nil
+				}
+				depth++
+				continue
+			}
+
+			var r resource
+			if err := decoder.DecodeElement(&r, &t); err != nil {
+				return fmt.Errorf("%v: %v", file, err)
+			}
+			translatable := r.Translatable != "false"
+
+			switch t.Name.Local {
+			case "string":
+				value, html := parseValue(r.Value)
+				*rows = append(*rows, Row{Key: r.Name, Value: value, Comment: comment, HTML: html, Translatable: translatable})
+			case "plurals":
+				for _, item := range r.Items {
+					key := r.Name + "__pl_" + item.Quantity
+					value, html := parseValue(item.Value)
+					if match := stringReference.FindStringSubmatch(value); match != nil {
+						if match[1] == key {
+							// the quantity string was already imported, e.g. from a file generated by the android command
+							continue
+						}
+						references[len(*rows)] = match[1]
+					}
+					// the comment goes to the first quantity that gets imported
+					*rows = append(*rows, Row{Key: key, Value: value, Comment: comment, HTML: html, Translatable: translatable})
+					comment = ""
+				}
+			case "string-array":
+				for i, item := range r.Items {
+					value, html := parseValue(item.Value)
+					*rows = append(*rows, Row{Key: r.Name + "__" + strconv.Itoa(i), Value: value, Comment: comment, HTML: html, Translatable: translatable})
+				}
+				log.Printf("Warning: string-array %q of %v was imported as separate strings %v__<index>", r.Name, file, r.Name)
+			}
+			comment = ""
+		}
+	}
+}
+
+var xliff = regexp.MustCompile(`</?xliff:g[^>]*>`)
+
+const cdataStart, cdataEnd = "<![CDATA[", "]]>"
+
+// parseValue returns the unescaped value of the element, which is html if it contains markup or CDATA
+func parseValue(inner string) (value string, html bool) {
+	inner = strings.TrimSpace(xliff.ReplaceAllString(inner, ""))
+	if strings.HasPrefix(inner, cdataStart) && strings.HasSuffix(inner, cdataEnd) {
+		inner = inner[len(cdataStart) : len(inner)-len(cdataEnd)]
+		return android.Unescape(strings.Replace(inner, "]]]]><![CDATA[>", "]]>", -1)), true
+	}
+	if strings.Contains(inner, "<") {
+		return unescapeMarkup(inner), true
+	}
+
+	var text struct {
+		Value string `xml:",chardata"`
+	}
+	if err := xml.Unmarshal([]byte("<value>"+inner+"</value>"), &text); err != nil {
+		return android.Unescape(inner), false
+	}
+	return android.Unescape(text.Value), false
+}
+
+// tagPlaceholder replaces the elements of inline markup while the text gets unescaped
+const tagPlaceholder = "\x00"
+
+// unescapeMarkup unescapes the text of an inline html value, while its elements are kept as they are, e.g. the quotes of attributes
+func unescapeMarkup(inner string) string {
+	decoder := xml.NewDecoder(strings.NewReader(inner))
+	decoder.Strict = false
+
+	var text strings.Builder
+	var tags []string
+	var offset int64
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			break
+		}
+		raw := inner[offset:decoder.InputOffset()]
+		offset = decoder.InputOffset()
+		if _, ok := token.(xml.CharData); ok {
+			text.WriteString(raw)
+		} else {
+			text.WriteString(tagPlaceholder)
+			tags = append(tags, raw)
+		}
+	}
+	text.WriteString(inner[offset:])
+
+	parts := strings.Split(android.Unescape(text.String()), tagPlaceholder)
+	if len(parts) != len(tags)+1 {
+		// an escaped placeholder, which can't be restored
+		return android.Unescape(inner)
+	}
+	var unescaped strings.Builder
+	for i, part := range parts {
+		unescaped.WriteString(part)
+		if i < len(tags) {
+			unescaped.WriteString(tags[i])
+		}
+	}
+	return unescaped.String()
+}
@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		name, inner, value string
+		html               bool
+	}{
+		{"plain", "Hello, world!", "Hello, world!", false},
+		{"whitespace", "  Hello,\n        world!  ", "Hello, world!", false},
+		{"quoted", `"  Hello  "`, "  Hello  ", false},
+		{"escaped quotes", `What\'s \"This\"`, `What's "This"`, false},
+		{"entities", "Questions &amp; Answers &lt;3", "Questions & Answers <3", false},
+		{"newline", `First\nSecond`, "First\nSecond", false},
+		{"xliff", `Hello <xliff:g id="name">%1$s</xliff:g>`, "Hello %1$s", false},
+		{"cdata", `<![CDATA[Some <a href=\"https://example.com\">Link</a>]]>`, `Some <a href="https://example.com">Link</a>`, true},
+		{"inline html", `Some <b>bold</b> text`, "Some <b>bold</b> text", true},
+		{"inline html with attributes", `Read the <a href="https://example.com/terms">terms</a>, it\'s <font color="#ff0000">important</font>`, `Read the <a href="https://example.com/terms">terms</a>, it's <font color="#ff0000">important</font>`, true},
+		{"inline html with entities", `Terms <b>&amp;</b> conditions`, "Terms <b>&amp;</b> conditions", true},
+	}
+	for _, test := range tests {
+		value, html := parseValue(test.inner)
+		if value != test.value || html != test.html {
+			t.Errorf("%v: got %q (html %v), want %q (html %v)", test.name, value, html, test.value, test.html)
+		}
+	}
+}
+
+func TestImportAndroid(t *testing.T) {
+	folder := "testdata/android"
+	resFolder = &folder
+
+	sheets, err := AndroidImporter{}.Import()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheets) != 2 {
+		t.Fatalf("expected the default and de sheet, values-night is no locale, got %d sheets", len(sheets))
+	}
+
+	expected := map[string][]Row{
+		"default": {
+			{"greeting_hello", "Hello, %1$s!", "Shown on the start screen", false, true, ""},
+			{"greeting_quote", `What's "This"`, "", false, true, ""},
+			{"greeting_link", `Read the <a href="https://example.com/terms">terms</a> &amp; conditions`, "", true, true, ""},
+			{"greeting_cdata", "Some <b>bold</b> text", "", true, true, ""},
+			{"greeting_spaces", "  Two  spaces  ", "", false, true, ""},
+			{"app_name", "Example", "", false, false, ""},
+			{"cart_items__pl_one", "%d item", "The items in the cart", false, true, ""},
+			{"cart_items__pl_other", "%d items", "", false, true, ""},
+			{"planets_names__0", "Mercury", "", false, true, ""},
+			{"planets_names__1", "Venus", "", false, true, ""},
+		},
+		"de": {
+			{"cart_items__pl_one", "%d Artikel", "", false, true, ""},
+			{"cart_items__pl_other", "%d Artikel", "The items in the cart", false, true, ""},
+		},
+	}
+	for _, sheet := range sheets {
+		if rows := expected[sheet.Locale.Name]; !reflect.DeepEqual(sheet.Rows, rows) {
+			t.Errorf("%v: got %+v, want %+v", sheet.Locale.Name, sheet.Rows, rows)
+		}
+	}
+}
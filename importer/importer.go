@@ -0,0 +1,61 @@
+package importer
+
+import (
+	"log"
+
+	"github.com/bleeding182/localization/writer"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+// Importer reads the strings of an existing project, so they can be added to the sheet
+type Importer interface {
+	Tag() string
+	// Import returns one sheet per locale found
+	Import() ([]*Sheet, error)
+
+	// RegisterCommand adds the subcommand with the Tag() to the import command
+	RegisterCommand(command *kingpin.CmdClause)
+}
+
+// Sheet of a single locale, in the order the strings were found
+type Sheet struct {
+	Locale writer.Locale
+	Rows   []Row
+
+	keys map[string]int // index of the row with the key
+}
+
+// Row of a sheet, using the keys and the unescaped values expected by the writers
+type Row struct {
+	Key, Value, Comment string
+	HTML                bool
+	Translatable        bool
+	Module              string // e.g. the table of an iOS string
+}
+
+// sheetOf returns the sheet of the locale, adding a new one if required
+func sheetOf(sheets *[]*Sheet, locale writer.Locale) *Sheet {
+	for _, sheet := range *sheets {
+		if sheet.Locale == locale {
+			return sheet
+		}
+	}
+	sheet := &Sheet{Locale: locale, keys: make(map[string]int)}
+	*sheets = append(*sheets, sheet)
+	return sheet
+}
+
+// add the rows to the sheet, skipping keys that were already imported, e.g. from another folder of the same locale
+func (sheet *Sheet) add(rows ...Row) {
+	for _, row := range rows {
+		if i, ok := sheet.keys[row.Key]; ok {
+			if sheet.Rows[i].Value != row.Value {
+				log.Printf("Warning: %v contains %q twice with different values, skipping %q", sheet.Locale, row.Key, row.Value)
+			}
+			continue
+		}
+		sheet.keys[row.Key] = len(sheet.Rows)
+		sheet.Rows = append(sheet.Rows, row)
+	}
+}
@@ -0,0 +1,403 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bleeding182/localization/writer"
+	"github.com/bleeding182/localization/writer/ios"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const tagIos = "ios"
+
+var iosFolder *string
+
+type IOSImporter struct{}
+
+func (importer IOSImporter) Tag() string {
+	return tagIos
+}
+
+func (importer IOSImporter) RegisterCommand(command *kingpin.CmdClause) {
+	subcommand := command.Command(tagIos, "Import the .strings and .stringsdict files of all *.lproj folders. Tables other than Localizable and LocalizableGen will be imported as modules, Base.lproj as the default locale.")
+	iosFolder = subcommand.Arg("dir", "The directory containing the *.lproj folders, which will be searched recursively.").Required().ExistingDir()
+}
+
+func (importer IOSImporter) Import() ([]*Sheet, error) {
+	var sheets []*Sheet
+	err := filepath.Walk(*iosFolder, func(folder string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() || filepath.Ext(folder) != ".lproj" {
+			return err
+		}
+		locale, err := lprojLocale(info.Name())
+		if err != nil {
+			log.Printf("Warning: skipping %v: %v", folder, err)
+			return filepath.SkipDir
+		}
+		rows, err := importLproj(folder)
+		if err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			sheetOf(&sheets, locale).add(rows...)
+		}
+		return filepath.SkipDir
+	})
+	return sheets, err
+}
+
+// lprojLocale returns the locale of the folder, e.g. de.lproj, pt-BR.lproj or zh_TW.lproj. Base.lproj contains the default locale.
+func lprojLocale(folder string) (writer.Locale, error) {
+	tag := strings.Replace(strings.TrimSuffix(folder, ".lproj"), "_", "-", -1)
+	if tag == "Base" {
+		tag = writer.DefaultLocale
+	}
+	locale, err := writer.ParseLocale(folder, tag)
+	if err != nil {
+		return locale, err
+	}
+	locale.Name = locale.String()
+	return locale, nil
+}
+
+// importLproj reads all tables of the folder. Plurals of a .stringsdict replace any string with the same key in the .strings file of the table.
+func importLproj(folder string) ([]Row, error) {
+	files, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var localizable, plurals []Row
+	pluralKeys := make(map[string]bool)
+	for _, file := range files {
+		filename := filepath.Join(folder, file.Name())
+		table := tableModule(file.Name())
+		switch filepath.Ext(file.Name()) {
+		case ".strings":
+			rows, err := importStrings(filename)
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				row.Module = table
+				if file.Name() == "InfoPlist.strings" {
+					row.Key = ios.InfoPlistGroup + "__" + row.Key
+					row.Module = ""
+				}
+				localizable = append(localizable, row)
+			}
+		case ".stringsdict":
+			rows, keys, err := importStringsDict(filename)
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				row.Module = table
+				plurals = append(plurals, row)
+			}
+			for _, key := range keys {
+				pluralKeys[key] = true
+			}
+		}
+	}
+
+	var rows []Row
+	for _, row := range localizable {
+		if !pluralKeys[row.Key] {
+			rows = append(rows, row)
+		}
+	}
+	return append(rows, plurals...), nil
+}
+
+// tableModule returns the module of the table, the default tables belong to no module
+func tableModule(file string) string {
+	table := strings.TrimSuffix(file, filepath.Ext(file))
+	if table == "Localizable" || table == "LocalizableGen" || table == "InfoPlist" {
+		return ""
+	}
+	return table
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// readText reads the file as UTF-8 or UTF-16, which was the default encoding of .strings files before Xcode 6
+func readText(filename string) (string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	var utf16 encoding.Encoding
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}) || bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		utf16 = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	case len(data) > 1 && data[0] == 0:
+		utf16 = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case len(data) > 1 && data[1] == 0:
+		utf16 = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	}
+	if utf16 != nil {
+		if data, err = utf16.NewDecoder().Bytes(data); err != nil {
+			return "", fmt.Errorf("%v: %v", filename, err)
+		}
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("%v is neither UTF-8 nor UTF-16", filename)
+	}
+	return string(data), nil
+}
+
+// importStrings parses a .strings file, which is an old-style plist of `"key" = "value";` entries, optionally wrapped in braces.
+// The last comment before an entry will be used as its comment, except for `/** group **/` markers and comments followed by an
+// empty line, e.g. the header created by Xcode.
+func importStrings(filename string) ([]Row, error) {
+	text, err := readText(filename)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := parseStrings(text)
+	if err != nil {
+		return nil, fmt.Errorf("%v:%v", filename, err)
+	}
+	return rows, nil
+}
+
+// parseStrings parses the content of a .strings file, errors start with the line
+func parseStrings(text string) ([]Row, error) {
+	p := &stringsParser{text: text}
+	fail := func(format string, args ...interface{}) ([]Row, error) {
+		return nil, fmt.Errorf("%d: %v", p.line(), fmt.Sprintf(format, args...))
+	}
+
+	var rows []Row
+	p.skip()
+	braces := p.next('{')
+	if braces {
+		// a comment before the braces belongs to the file
+		p.comment = ""
+	}
+	for {
+		p.skip()
+		if p.eof() || (braces && p.next('}')) {
+			break
+		}
+		comment := p.comment
+
+		key, err := p.token()
+		if err != nil {
+			return fail("%v", err)
+		}
+		value := key
+		p.skip()
+		if p.next('=') {
+			p.skip()
+			if value, err = p.token(); err != nil {
+				return fail("%v", err)
+			}
+			p.skip()
+		}
+		if !p.next(';') {
+			return fail("expected ; after %q", key)
+		}
+		p.comment = ""
+
+		rows = append(rows, Row{Key: key, Value: value, Comment: comment, HTML: writer.HasMarkup(value), Translatable: true})
+	}
+	if p.skip(); !p.eof() {
+		return fail("unexpected %q", p.text[p.i:p.i+1])
+	}
+	return rows, nil
+}
+
+type stringsParser struct {
+	text     string
+	i        int
+	comment  string // the last comment read by skip, unless it was followed by an empty line
+	newlines int    // since the last comment
+}
+
+func (p *stringsParser) eof() bool {
+	return p.i >= len(p.text)
+}
+
+func (p *stringsParser) line() int {
+	return strings.Count(p.text[:p.i], "\n") + 1
+}
+
+// next consumes c if it is the next character
+func (p *stringsParser) next(c byte) bool {
+	if !p.eof() && p.text[p.i] == c {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// skip whitespace and comments
+func (p *stringsParser) skip() {
+	for !p.eof() {
+		rest := p.text[p.i:]
+		var comment string
+		switch {
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				comment, p.i = rest[2:], len(p.text)
+				break
+			}
+			comment = rest[2 : end+2]
+			p.i += end + 4
+		case strings.HasPrefix(rest, "//"):
+			end := strings.Index(rest, "\n")
+			if end < 0 {
+				end = len(rest)
+			}
+			comment = rest[2:end]
+			p.i += end
+		case rest[0] == '\n':
+			if p.newlines++; p.newlines > 1 {
+				p.comment = ""
+			}
+			p.i++
+			continue
+		case strings.IndexByte(" \t\r", rest[0]) >= 0:
+			p.i++
+			continue
+		default:
+			return
+		}
+		if comment = strings.TrimSpace(comment); strings.HasPrefix(comment, "*") {
+			comment = "" // a `/** group **/` marker
+		}
+		p.comment, p.newlines = comment, 0
+	}
+}
+
+var unquoted = regexp.MustCompile(`^[a-zA-Z0-9_.$:/-]+`)
+
+// token reads a quoted string, unescaping it, or an unquoted word
+func (p *stringsParser) token() (string, error) {
+	if p.eof() {
+		return "", fmt.Errorf("unexpected end of file")
+	}
+	if !p.next('"') {
+		word := unquoted.FindString(p.text[p.i:])
+		if word == "" {
+			return "", fmt.Errorf("unexpected %q", p.text[p.i:p.i+1])
+		}
+		p.i += len(word)
+		return word, nil
+	}
+
+	var value strings.Builder
+	for !p.eof() {
+		c := p.text[p.i]
+		p.i++
+		switch {
+		case c == '"':
+			return value.String(), nil
+		case c == '\\' && !p.eof():
+			c = p.text[p.i]
+			p.i++
+			switch c {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			case 'U', 'u':
+				if p.i+4 <= len(p.text) {
+					if code, err := strconv.ParseUint(p.text[p.i:p.i+4], 16, 32); err == nil {
+						value.WriteRune(rune(code))
+						p.i += 4
+						continue
+					}
+				}
+				value.WriteByte(c)
+			default:
+				value.WriteByte(c)
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+var pluralVariable = regexp.MustCompile(`%#@([^@]+)@`)
+
+// importStringsDict returns the quantity strings of all plurals with a single variable, along with the keys of the plurals.
+// The text around the variable, e.g. `%#@count@ left`, is added to every quantity.
+func importStringsDict(filename string) ([]Row, []string, error) {
+	text, err := readText(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	root, err := parsePlist(text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	plurals, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("%v: expected a dict", filename)
+	}
+
+	var rows []Row
+	var keys []string
+	for _, key := range sortedKeys(plurals) {
+		plural, _ := plurals[key].(map[string]interface{})
+		format, _ := plural["NSStringLocalizedFormatKey"].(string)
+		variables := pluralVariable.FindAllStringSubmatchIndex(format, -1)
+		if len(variables) != 1 {
+			log.Printf("Warning: skipping plural %q of %v, only a single variable is supported in %q", key, filename, format)
+			continue
+		}
+		variable := variables[0]
+		prefix, suffix := format[:variable[0]], format[variable[1]:]
+		rules, _ := plural[format[variable[2]:variable[3]]].(map[string]interface{})
+
+		var quantities []string
+		for quantity := range rules {
+			if writer.IsQuantity(quantity) {
+				quantities = append(quantities, quantity)
+			}
+		}
+		sort.Slice(quantities, func(i, j int) bool {
+			return writer.QuantityOf(quantities[i]) < writer.QuantityOf(quantities[j])
+		})
+
+		keys = append(keys, key)
+		for _, quantity := range quantities {
+			value, _ := rules[quantity].(string)
+			value = prefix + value + suffix
+			rows = append(rows, Row{Key: key + "__pl_" + quantity, Value: value, HTML: writer.HasMarkup(value), Translatable: true})
+		}
+	}
+	return rows, keys, nil
+}
+
+func sortedKeys(dict map[string]interface{}) []string {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportIOS(t *testing.T) {
+	folder := "testdata/ios"
+	iosFolder = &folder
+
+	sheets, err := IOSImporter{}.Import()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	greeting := func(value string) []Row {
+		return []Row{{Key: "greeting_hello", Value: value, Translatable: true}}
+	}
+	expected := map[string][]Row{
+		"default": {
+			{Key: "checkout_pay", Value: "Pay %@", Translatable: true, Module: "Checkout"},
+			{Key: "infoplist__NSCameraUsageDescription", Value: "We need the camera to scan codes", Comment: "Camera permission", Translatable: true},
+			{Key: "greeting_hello", Value: "Hello, %@!", Comment: "Shown on the start screen", Translatable: true},
+			{Key: "greeting_quote", Value: "What's \"This\"\nNext line", Translatable: true},
+			{Key: "greeting_cafe", Value: "Café ☕", Comment: "The name of the café", Translatable: true},
+			{Key: "greeting_unquoted", Value: "Unquoted", Translatable: true},
+			{Key: "checkout_link", Value: `Read the <a href="https://example.com">terms</a>`, HTML: true, Translatable: true},
+			{Key: "cart_items__pl_one", Value: "Only %d item left", Translatable: true},
+			{Key: "cart_items__pl_other", Value: "Only %d items left", Translatable: true},
+		},
+		"de":    {{Key: "greeting_hello", Value: "Hallo, %@!", Comment: "Begrüßung", Translatable: true}},
+		"fr":    greeting("Bonjour, %@ !"),
+		"pt-BR": greeting("Olá, %@!"),
+	}
+	if len(sheets) != len(expected) {
+		t.Errorf("expected %d sheets, got %d", len(expected), len(sheets))
+	}
+	for _, sheet := range sheets {
+		if rows := expected[sheet.Locale.Name]; !reflect.DeepEqual(sheet.Rows, rows) {
+			t.Errorf("%v: got %+v, want %+v", sheet.Locale.Name, sheet.Rows, rows)
+		}
+	}
+}
+
+func TestParseStringsErrors(t *testing.T) {
+	tests := map[string]string{
+		`"key" = "value"`:         "expected ; after",
+		`"key" = "value`:          "unterminated string",
+		`"key" = "value"; }`:      "unexpected",
+		"\"a\" = \"b\";\n\"c\" =": "2: unexpected end of file",
+	}
+	for text, expected := range tests {
+		if _, err := parseStrings(text); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%q: expected an error containing %q, got %v", text, expected, err)
+		}
+	}
+}
+
+func TestParsePlist(t *testing.T) {
+	plist, err := parsePlist(`<?xml version="1.0" encoding="UTF-16"?>
+<plist version="1.0">
+<dict>
+    <key>name</key>
+    <string>Example &amp; more</string>
+    <key>list</key>
+    <array>
+        <string>a</string>
+        <dict><key>b</key><string>c</string></dict>
+    </array>
+</dict>
+</plist>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"name": "Example & more",
+		"list": []interface{}{"a", map[string]interface{}{"b": "c"}},
+	}
+	if !reflect.DeepEqual(plist, expected) {
+		t.Errorf("got %#v, want %#v", plist, expected)
+	}
+
+	if plist, err := parsePlist(""); err != nil || len(plist.(map[string]interface{})) != 0 {
+		t.Errorf("an empty plist should be an empty dict, got %v, %v", plist, err)
+	}
+}
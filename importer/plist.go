@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// parsePlist parses an xml property list. Dicts are returned as map[string]interface{}, arrays as []interface{} and all other values as string.
+func parsePlist(text string) (interface{}, error) {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	// the text was already decoded, e.g. from UTF-16
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			// an empty plist, e.g. a .stringsdict without any plurals
+			return map[string]interface{}{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local != "plist" {
+			return parsePlistValue(decoder, start)
+		}
+	}
+}
+
+func parsePlistValue(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]interface{})
+		key := ""
+		err := parsePlistChildren(decoder, func(child xml.StartElement) error {
+			if child.Name.Local == "key" {
+				return decoder.DecodeElement(&key, &child)
+			}
+			value, err := parsePlistValue(decoder, child)
+			dict[key] = value
+			return err
+		})
+		return dict, err
+	case "array":
+		var array []interface{}
+		err := parsePlistChildren(decoder, func(child xml.StartElement) error {
+			value, err := parsePlistValue(decoder, child)
+			array = append(array, value)
+			return err
+		})
+		return array, err
+	}
+
+	var value string
+	err := decoder.DecodeElement(&value, &start)
+	return value, err
+}
+
+// parsePlistChildren calls parse for every child element until the end of the parent
+func parsePlistChildren(decoder *xml.Decoder, parse func(child xml.StartElement) error) error {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			if err := parse(t); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const lintCommand = "lint"
+
+const (
+	termColumnName           = "term"
+	doNotTranslateColumnName = "do-not-translate"
+)
+
+var strictLint *bool
+
+func RegisterLintCommand(app *kingpin.Application) {
+	command := app.Command(lintCommand, "Check the translations against the --glossary sheet, with a term column, a column per locale with its approved translation, e.g. de, and a do-not-translate column for brand names.")
+	strictLint = command.Flag("strict", "Exit with an error if any problem was found.").Bool()
+}
+
+// glossaryTerm is a row of the glossary sheet
+type glossaryTerm struct {
+	term           string
+	pattern        *regexp.Regexp    // matches the term as a whole word, ignoring case
+	translations   map[string]string // approved translation by sheet title
+	doNotTranslate bool
+}
+
+// parseGlossary reads the terms of the glossary sheet, where every column besides term and do-not-translate is a locale
+func parseGlossary(values [][]interface{}) ([]glossaryTerm, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("the glossary sheet %q is empty", *glossaryTitle)
+	}
+	header := make([]string, len(values[0]))
+	for i, cell := range values[0] {
+		header[i] = strings.TrimSpace(cell.(string))
+	}
+	termIndex, doNotTranslateIndex := indexOf(header, termColumnName), indexOf(header, doNotTranslateColumnName)
+	if termIndex < 0 {
+		return nil, fmt.Errorf("the glossary sheet %q needs a %q column", *glossaryTitle, termColumnName)
+	}
+
+	var terms []glossaryTerm
+	for r, row := range values[1:] {
+		term := strings.TrimSpace(parse(row, termIndex))
+		if term == "" {
+			continue
+		}
+		t := glossaryTerm{
+			term:         term,
+			pattern:      regexp.MustCompile(`(?i)(^|[^\pL\pN])` + regexp.QuoteMeta(term) + `($|[^\pL\pN])`),
+			translations: make(map[string]string),
+		}
+		if doNotTranslate, ok := parseBool(parse(row, doNotTranslateIndex)); ok {
+			t.doNotTranslate = doNotTranslate
+		} else if doNotTranslateIndex >= 0 && parse(row, doNotTranslateIndex) != "" {
+			log.Printf("Warning: ignoring %v %q of %q in row %d of the glossary", doNotTranslateColumnName, parse(row, doNotTranslateIndex), term, r+2)
+		}
+		for i, column := range header {
+			if i == termIndex || i == doNotTranslateIndex || column == "" {
+				continue
+			}
+			if translation := strings.TrimSpace(parse(row, i)); translation != "" {
+				t.translations[column] = translation
+			}
+		}
+		terms = append(terms, t)
+	}
+	return terms, nil
+}
+
+// Lint checks the translations of every locale against the glossary, prints a report per locale and returns the number of problems
+func Lint(out io.Writer, entrySets []*EntrySet, glossary [][]interface{}) (int, error) {
+	terms, err := parseGlossary(glossary)
+	if err != nil {
+		return 0, err
+	}
+
+	sheets := parseSheets(entrySets)
+	removeExcluded(sheets)
+	removeUntranslatable(sheets)
+	base := defaultSheet(sheets)
+	if base == nil {
+		return 0, fmt.Errorf("missing the default sheet to lint against")
+	}
+	sources := make(map[string]string)
+	for _, ls := range base.Data {
+		sources[ls.Key.Original()] = ls.Value
+	}
+
+	sort.Slice(sheets, func(i, j int) bool {
+		return sheets[i].Locale.Name < sheets[j].Locale.Name
+	})
+	count := 0
+	for _, sheet := range sheets {
+		if sheet == base {
+			continue
+		}
+
+		var problems []string
+		for _, ls := range sheet.Data {
+			source := sources[ls.Key.Original()]
+			if ls.Value == "" || source == "" {
+				continue
+			}
+			for _, term := range terms {
+				if !term.pattern.MatchString(source) {
+					continue
+				}
+				if term.doNotTranslate {
+					if !strings.Contains(ls.Value, term.term) {
+						problems = append(problems, fmt.Sprintf("%v (row %d): %q must not be translated, %q", ls.Key.Original(), ls.Row, term.term, ls.Value))
+					}
+					continue
+				}
+				translation, ok := term.translations[sheet.Locale.Name]
+				if ok && !strings.Contains(strings.ToLower(ls.Value), strings.ToLower(translation)) {
+					problems = append(problems, fmt.Sprintf("%v (row %d): %q should be translated as %q, %q", ls.Key.Original(), ls.Row, term.term, translation, ls.Value))
+				}
+			}
+		}
+		if len(problems) == 0 {
+			continue
+		}
+		count += len(problems)
+
+		fmt.Fprintf(out, "%v: %d glossary problems\n", sheet.Locale.Name, len(problems))
+		for _, problem := range problems {
+			fmt.Fprintf(out, "    %v\n", problem)
+		}
+	}
+	return count, nil
+}
+
+// strictError fails the lint with --strict if any problem was found
+func strictError(count int) error {
+	if count > 0 && *strictLint {
+		return fmt.Errorf("found %d glossary problems", count)
+	}
+	return nil
+}
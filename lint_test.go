@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func glossary() [][]interface{} {
+	return [][]interface{}{
+		row("term", "de", "do-not-translate", "fr"),
+		row("cart", "Warenkorb", "", "panier"),
+		row("Example Pay", "", "yes"),
+		row(""),
+		row("checkout", "", "no"),
+	}
+}
+
+func TestParseGlossary(t *testing.T) {
+	parseArgs(t, "lint")
+	terms, err := parseGlossary(glossary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(terms) != 3 {
+		t.Fatalf("expected 3 terms without the empty row, got %+v", terms)
+	}
+	if terms[0].term != "cart" || !reflect.DeepEqual(terms[0].translations, map[string]string{"de": "Warenkorb", "fr": "panier"}) || terms[0].doNotTranslate {
+		t.Errorf("got %+v", terms[0])
+	}
+	if !terms[1].doNotTranslate || len(terms[1].translations) != 0 {
+		t.Errorf("Example Pay should not be translated, got %+v", terms[1])
+	}
+
+	// whole words only, ignoring case
+	for source, want := range map[string]bool{
+		"Your cart":          true,
+		"CART":               true,
+		"Cart, then pay":     true,
+		"Add to cart.":       true,
+		"Carts":              false,
+		"Cartography":        false,
+		"Shopping_cart_icon": true,
+	} {
+		if got := terms[0].pattern.MatchString(source); got != want {
+			t.Errorf("cart should match %q: %v, got %v", source, want, got)
+		}
+	}
+
+	if _, err := parseGlossary([][]interface{}{row("de", "fr")}); err == nil || !strings.Contains(err.Error(), `"term" column`) {
+		t.Errorf("expected an error for the missing term column, got %v", err)
+	}
+	if _, err := parseGlossary(nil); err == nil {
+		t.Error("expected an error for an empty glossary")
+	}
+}
+
+func TestLint(t *testing.T) {
+	header := row("key", "value")
+	entrySets := []*EntrySet{
+		{Locale: "default", Headers: header, Values: [][]interface{}{
+			row("cart_title", "Your cart"),
+			row("cart_pay", "Checkout with Example Pay"),
+			row("cart_art", "Cartography"),
+		}},
+		{Locale: "de", Headers: header, Values: [][]interface{}{
+			row("cart_title", "Dein warenkorb"), // approved terms ignore case
+			row("cart_pay", "Bezahlen mit Beispiel Pay"),
+			row("cart_art", "Kartografie"),
+		}},
+		{Locale: "fr", Headers: header, Values: [][]interface{}{
+			row("cart_title", "Votre chariot"),
+			row("cart_pay", "Payer avec Example Pay"),
+		}},
+	}
+
+	parseArgs(t, "lint")
+	var out bytes.Buffer
+	count, err := Lint(&out, entrySets, glossary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `de: 1 glossary problems
+    cart_pay (row 3): "Example Pay" must not be translated, "Bezahlen mit Beispiel Pay"
+fr: 1 glossary problems
+    cart_title (row 2): "cart" should be translated as "panier", "Votre chariot"
+`
+	if count != 2 || out.String() != want {
+		t.Errorf("Lint() = %d problems\n%v\nwant 2\n%v", count, out.String(), want)
+	}
+
+	if err := strictError(count); err != nil {
+		t.Errorf("problems are only reported without --strict, got %v", err)
+	}
+	parseArgs(t, "lint", "--strict")
+	if err := strictError(count); err == nil {
+		t.Error("expected an error with --strict")
+	}
+	if err := strictError(0); err != nil {
+		t.Errorf("expected no error without problems, got %v", err)
+	}
+
+	if _, err := Lint(&out, entrySets[1:], glossary()); err == nil {
+		t.Error("expected an error without the default sheet")
+	}
+}
@@ -2,17 +2,19 @@ package main
 
 import (
 	"fmt"
-	"html"
 	"log"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/bleeding182/localization/writer"
 	"github.com/bleeding182/localization/writer/android"
+	"github.com/bleeding182/localization/writer/compose"
 	"github.com/bleeding182/localization/writer/ios"
+	"github.com/bleeding182/localization/writer/moko"
 	"gopkg.in/alecthomas/kingpin.v2"
 )
 
@@ -26,10 +28,14 @@ Your sheet should contain the following columns: [key, value, android, ios, comm
 
 Keys:
     The keys used for your strings must match "<group>_<identifier>". If yor group name consists of multiple parts you can use "__" to mark it accordingly. Groups are used to group strings together.
+    Use --keyPattern dotted for keys like "<group>.<sub>.<identifier>", or pass your own regular expression. Rows with keys that don't match are skipped with a warning.
 
 Plurals:
-	Plurals must be marked by the "__pl_<zero|one|two|few|many|other>" suffix on your key. If supported by the export target, they will be exported and grouped accordingly.
+	Plurals must be marked by the "__pl_<zero|one|two|few|many|other>" suffix on your key, or "#<quantity>" with --keyPattern dotted. If supported by the export target, they will be exported and grouped accordingly.
 	
+Locales:
+	Every sheet is a locale and its name must be a BCP-47 tag, e.g. "de" or "pt-BR". Your base language goes into a sheet named "default". Use --localeAlias to map other sheet names. Hidden sheets and sheets starting with "_" are skipped. The "glossary" sheet is only used by lint.
+
 Values:
     Values may be escaped by the target platform or modified in some other way. If you want to override a value you can place it in a column of the target platforms name.
 
@@ -39,44 +45,102 @@ For more information please also check https://github.com/bleeding182/localizati
 var (
 	app = kingpin.New("localization", appDescription).Version(version)
 	// verbose = app.Flag("verbose", "Verbose logs. Use this to debug potential errors.").Bool()
-	sheetID = app.Flag("sheetID", "ID of the spreadsheet to use.").Short('s').Required().String()
+	sheetID         = app.Flag("sheetID", "ID of the spreadsheet to use, required to export.").Short('s').String()
+	keyPattern      = app.Flag("keyPattern", "The grammar of your keys, either `default`, `dotted` (group.sub.identifier#quantity) or a regular expression with the named groups key, group, identifier and an optional quantity.").Default("default").String()
+	includeSheets   = app.Flag("sheet", "Only export this sheet, by title or gid. Can be repeated.").Strings()
+	excludeSheets   = app.Flag("excludeSheet", "Don't export this sheet, by title or gid. Can be repeated.").Strings()
+	includeHidden   = app.Flag("includeHidden", "Export hidden sheets, which are skipped by default.").Bool()
+	ignorePattern   = app.Flag("ignorePattern", "Skip sheets with a title matching this regular expression, e.g. helper sheets like _README or _archive.").Default("^_").Regexp()
+	sheetRangeLimit = app.Flag("range", "The range of columns to read from every sheet, e.g. A:M. Defaults to all used cells.").PlaceHolder("A:M").String()
+	concurrency     = app.Flag("concurrency", "The maximum number of concurrent requests to read the sheets, in batches of 10 sheets each.").Default("2").Int()
+	gridData        = app.Flag("gridData", "Read the notes and formatting of all cells. Notes are added to the comments and rows with the --excludeFormat on their key are excluded.").Bool()
+	excludeFormat   = app.Flag("excludeFormat", "Exclude rows with this format on their key when using --gridData, either `strikethrough`, `none` or a background color like #ff0000.").Default("strikethrough").String()
+	glossaryTitle   = app.Flag("glossary", "The title of the glossary sheet used by lint. It is never exported as a locale.").Default("glossary").String()
+	localeAliases   = app.Flag("localeAlias", "Map a sheet title to a BCP-47 locale, e.g. --localeAlias zh_TW=zh-Hant-TW. Can be repeated.").PlaceHolder("TITLE=LOCALE").StringMap()
 )
 
 func main() {
 	RegisterCommands(app)
+	RegisterImportCommands(app)
+	RegisterPushCommand(app)
+	RegisterLintCommand(app)
 	command := kingpin.MustParse(app.Parse(os.Args[1:]))
 
-	resp, err := loadSpreadSheet()
+	if err := writer.SetKeyPattern(*keyPattern); err != nil {
+		log.Fatal(err)
+	}
+	if isImport(command) {
+		Import(command)
+		return
+	}
+
+	if *sheetID == "" {
+		app.Fatalf("required flag --sheetID not provided")
+	}
+	if command == pushCommand {
+		Push()
+		return
+	}
+
+	resp, err := loadSpreadSheet(command == lintCommand)
 
 	if err != nil {
 		log.Fatalf("Unable to retrieve data from sheet. %v", err)
 	}
 
-	entrySets := make([]*EntrySet, len(resp))
-	for i, data := range resp {
-		sheet := data.sheet
-		entrySets[i] = &EntrySet{
+	var glossary [][]interface{}
+	entrySets := make([]*EntrySet, 0, len(resp))
+	for _, data := range resp {
+		if data.glossary {
+			glossary = data.values
+			continue
+		}
+		entrySet := &EntrySet{
 			GID:     data.sheetID,
-			Locale:  strings.Split(sheet.Range, "!")[0],
-			Headers: sheet.Values[:1][0],
-			Values:  sheet.Values[1:],
+			Locale:  data.title,
+			Headers: data.values[0],
+			Values:  data.values[1:],
+		}
+		if *gridData {
+			entrySet.Notes = data.notes[1:]
+			entrySet.Excluded = data.excluded[1:]
+		}
+		entrySets = append(entrySets, entrySet)
+	}
+
+	if command == lintCommand {
+		count, err := Lint(os.Stdout, entrySets, glossary)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := strictError(count); err != nil {
+			log.Fatal(err)
 		}
+		return
 	}
 
-	wg := Export(command, *sheetID, entrySets)
+	wg, locales := Export(command, *sheetID, entrySets)
 
 	wg.Wait()
+
+	if finisher, ok := Writers[command].(writer.Finisher); ok {
+		finisher.Finish(locales)
+	}
 }
 
 var outputFolder *string
 
 var (
-	keyColumnName, valueColumnName, commentColumnName *string
+	keyColumnName, valueColumnName, commentColumnName, htmlColumnName, moduleColumnName, platformsColumnName, translatableColumnName, maxLengthColumnName *string
+	reportMissing                                                                                                                                         *bool
+	modules                                                                                                                                               *map[string]string
 )
 
 var Writers = map[string]writer.Writer{
 	"ios":     ios.IOSWriter{},
 	"android": android.AndroidWriter{},
+	"compose": compose.ComposeWriter{},
+	"moko":    moko.MokoWriter{},
 }
 
 type EntrySet struct {
@@ -85,6 +149,10 @@ type EntrySet struct {
 
 	Headers []interface{}
 	Values  [][]interface{}
+
+	// optional metadata of every row in Values, read with --gridData
+	Notes    [][]string
+	Excluded []bool
 }
 
 func RegisterCommands(app *kingpin.Application) {
@@ -99,16 +167,26 @@ func RegisterCommands(app *kingpin.Application) {
 	keyColumnName = app.Flag("key", "Override the name of the key column").Default("key").Short('k').String()
 	valueColumnName = app.Flag("value", "Override the name of the value column").Default("value").Short('v').String()
 	commentColumnName = app.Flag("comment", "Override the name of the comment column").Default("comment").Short('c').String()
+	htmlColumnName = app.Flag("html", "Override the name of the html column. Rows without a value in this column are checked for html tags instead.").Default("html").String()
+	app.Flag("infoPlistGroup", "Strings of this group, e.g. infoplist__NSCameraUsageDescription, will be exported to InfoPlist.strings on iOS using the plist key and skipped by all other exports.").Default("infoplist").StringVar(&ios.InfoPlistGroup)
+	moduleColumnName = app.Flag("module", "Override the name of the module column. A module in this column takes precedence over --moduleMap.").Default("module").String()
+	platformsColumnName = app.Flag("platforms", "Override the name of the platforms column. Rows can be limited to platforms, e.g. `android,web`, or excluded from them, e.g. `!ios`.").Default("platforms").String()
+	translatableColumnName = app.Flag("translatable", "Override the name of the translatable column. Rows marked with `false` are only exported from the default sheet.").Default("translatable").String()
+	maxLengthColumnName = app.Flag("maxLength", "Override the name of the max length column. Translations longer than the max length of their key in the default sheet are reported, with format arguments replaced by sample values.").Default("maxLength").String()
+	reportMissing = app.Flag("missing", "Print a report of all translatable strings that are missing or empty in a locale.").Bool()
+	modules = app.Flag("moduleMap", "Export all strings of a group into a separate module, e.g. --moduleMap checkout=Checkout. Can be repeated.").PlaceHolder("GROUP=MODULE").StringMap()
 }
 
 type sheet struct {
 	GID     string
-	Locale  string // e.g. "default" or "en", "en-us"
+	Locale  writer.Locale // e.g. "default" or "en", "pt-BR"
 	Columns map[string]int
 	Data    []writer.LocalizedString
 	Plurals map[string]writer.QuantityString
 	Headers *[]string
 	Model   *writer.LocalizationModel
+
+	FlavorModels map[string]*writer.LocalizationModel
 }
 
 func (sheet sheet) columnIndex(column string) int {
@@ -119,20 +197,22 @@ func (sheet sheet) columnIndex(column string) int {
 	return index
 }
 
-func Export(command string, sheetID string, entrySets []*EntrySet) (wg *sync.WaitGroup) {
+func Export(command string, sheetID string, entrySets []*EntrySet) (wg *sync.WaitGroup, locales []writer.Locale) {
 	wg = &sync.WaitGroup{}
 
 	timestamp := time.Now().Format(time.RFC3339)
 
-	sheetChan := make(chan *sheet)
-	for _, entrySet := range entrySets {
-		go parseEntrySetToSheet(entrySet, sheetChan)
+	sheets := parseSheets(entrySets)
+	removeExcluded(sheets)
+	validate(command, sheets)
+	removeUntranslatable(sheets)
+	inheritDefaults(sheets)
+	printOverflows(os.Stdout, sheets)
+	if *reportMissing {
+		printMissingTranslations(sheets)
 	}
 
-	sheets := make([]*sheet, len(entrySets))
-	for i := 0; i < len(entrySets); i++ {
-		sheets[i] = <-sheetChan
-
+	for i := range sheets {
 		sheets[i].Headers = &[]string{
 			//"Generated by github.com/bleeding182/localization v" + version,
 			"Do _not_ modify",
@@ -140,22 +220,65 @@ func Export(command string, sheetID string, entrySets []*EntrySet) (wg *sync.Wai
 			fmt.Sprintf("Last updated at %v", timestamp),
 		}
 
-		createSheetModel(command, sheets[i])
+		sheets[i].Model = createSheetModel(command, sheets[i], "")
+		sheets[i].FlavorModels = make(map[string]*writer.LocalizationModel)
+	}
+
+	for _, flavor := range flavors(sheets) {
+		models := make([]*writer.LocalizationModel, len(sheets))
+		overridden := false
+		for i := range sheets {
+			models[i] = createSheetModel(command, sheets[i], flavor)
+			overridden = overridden || hasOverrides(models[i])
+		}
+		if !overridden {
+			log.Printf("Skipping flavor %q, it has no overrides for %v", flavor, command)
+			continue
+		}
+		for i := range sheets {
+			sheets[i].FlavorModels[flavor] = models[i]
+		}
 	}
 
 	for _, sheet := range sheets {
+		locales = append(locales, sheet.Locale)
 		wg.Add(1)
 		go feedWriter(Writers[command], sheet, wg)
 	}
 	return
 }
 
-func parseEntrySetToSheet(entrySet *EntrySet, sheetChan chan *sheet) {
-	fmt.Sprintln("Sheet", entrySet.GID)
+// parseSheets parses all entry sets concurrently
+func parseSheets(entrySets []*EntrySet) []*sheet {
+	sheetChan := make(chan *sheet)
+	for _, entrySet := range entrySets {
+		locale, err := parseLocale(entrySet.Locale)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go parseEntrySetToSheet(entrySet, locale, sheetChan)
+	}
+
+	sheets := make([]*sheet, len(entrySets))
+	for i := 0; i < len(entrySets); i++ {
+		sheets[i] = <-sheetChan
+	}
+	return sheets
+}
+
+// parseLocale maps the sheet title to its locale, resolving any aliases first
+func parseLocale(title string) (writer.Locale, error) {
+	tag, ok := (*localeAliases)[title]
+	if !ok {
+		tag = title
+	}
+	return writer.ParseLocale(title, tag)
+}
 
+func parseEntrySetToSheet(entrySet *EntrySet, locale writer.Locale, sheetChan chan *sheet) {
 	var sheet = &sheet{
 		GID:     entrySet.GID,
-		Locale:  entrySet.Locale,
+		Locale:  locale,
 		Columns: make(map[string]int),
 		Data:    make([]writer.LocalizedString, 0, len(entrySet.Values)),
 		Plurals: make(map[string]writer.QuantityString),
@@ -169,21 +292,62 @@ func parseEntrySetToSheet(entrySet *EntrySet, sheetChan chan *sheet) {
 	keyIndex := sheet.columnIndex(*keyColumnName)
 	valueIndex := sheet.columnIndex(*valueColumnName)
 	commentIndex := sheet.columnIndex(*commentColumnName)
+	htmlIndex := sheet.columnIndex(*htmlColumnName)
+	moduleIndex := sheet.columnIndex(*moduleColumnName)
+	platformsIndex := sheet.columnIndex(*platformsColumnName)
+	translatableIndex := sheet.columnIndex(*translatableColumnName)
+	maxLengthIndex := sheet.columnIndex(*maxLengthColumnName)
 
-	for _, row := range entrySet.Values {
+	for r, row := range entrySet.Values {
 		key := parse(row, keyIndex)
 		if key == "" {
 			continue
 		}
 
-		compositeKey := writer.CompositeKeyOf(key)
+		compositeKey, err := writer.ParseKey(key)
+		if err != nil {
+			log.Printf("Warning: skipping row %d of sheet %q: %v", r+2, entrySet.Locale, err)
+			continue
+		}
 
 		s := writer.LocalizedString{
+			Row:     r + 2, // 1-based and below the header
 			Key:     compositeKey,
 			Value:   parse(row, valueIndex),
 			Comment: parse(row, commentIndex),
+			Module:  parse(row, moduleIndex),
 			Entries: make([]string, len(row)),
 		}
+		if r < len(entrySet.Notes) {
+			s.Notes = entrySet.Notes[r]
+			s.Comment = noteComment(s.Comment, s.Notes)
+		}
+		if r < len(entrySet.Excluded) {
+			s.Excluded = entrySet.Excluded[r]
+		}
+		for _, platform := range strings.Split(parse(row, platformsIndex), ",") {
+			if platform = strings.TrimSpace(platform); platform != "" {
+				s.Platforms = append(s.Platforms, platform)
+			}
+		}
+		if s.Module == "" {
+			s.Module = (*modules)[compositeKey.Group()]
+		}
+		s.Translatable = true
+		if translatable, ok := parseBool(parse(row, translatableIndex)); ok {
+			s.Translatable = translatable
+		}
+		if maxLength := strings.TrimSpace(parse(row, maxLengthIndex)); maxLength != "" {
+			if s.MaxLength, err = strconv.Atoi(maxLength); err != nil || s.MaxLength < 1 {
+				log.Printf("Warning: ignoring %v %q in row %d of sheet %q, it must be a positive number", *maxLengthColumnName, maxLength, r+2, entrySet.Locale)
+				s.MaxLength = 0
+			}
+		}
+		if html, ok := parseBool(parse(row, htmlIndex)); ok {
+			s.HTML = html
+		} else {
+			s.HTML = writer.HasMarkup(s.Value)
+		}
 		for i, c := range row {
 			s.Entries[i] = c.(string)
 		}
@@ -219,23 +383,66 @@ func parse(row []interface{}, index int) string {
 	return ""
 }
 
-func createSheetModel(tag string, sheet *sheet) {
-
-	overrideIndex, ok := sheet.Columns[tag]
-	if !ok {
-		overrideIndex = -1
+// parseBool parses a flag column. ok is false if the cell is empty or not a boolean value
+func parseBool(cell string) (value bool, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(cell)) {
+	case "true", "yes", "x", "1":
+		return true, true
+	case "false", "no", "0":
+		return false, true
 	}
+	return false, false
+}
 
+// createSheetModel builds the model for the platform. Values are taken from the first non-empty column of
+// `<tag>@<flavor>`, `value@<flavor>`, `<tag>` and `value`, where platform columns are used as-is.
+func createSheetModel(tag string, sheet *sheet, flavor string) *writer.LocalizationModel {
 	var w = Writers[tag]
 
+	value := func(ls writer.LocalizedString, column string) string {
+		if index := sheet.columnIndex(column); index >= 0 && index < len(ls.Entries) {
+			return ls.Entries[index]
+		}
+		return ""
+	}
+	// rawValue returns the flavored value, if any
+	rawValue := func(ls writer.LocalizedString) (string, bool) {
+		if flavor != "" {
+			if v := value(ls, *valueColumnName+"@"+flavor); v != "" {
+				return v, true
+			}
+		}
+		return ls.Value, false
+	}
+
+	plurals := make(map[string]writer.QuantityString)
+	for key, plural := range sheet.Plurals {
+		if isInfoPlist(tag, writer.CompositeKeyOf(plural.Key)) {
+			continue
+		}
+		values := make(map[writer.Quantity]writer.LocalizedString)
+		for quantity, ls := range plural.Values {
+			if ls.ExportsTo(tag) {
+				ls.Value, _ = rawValue(ls)
+				values[quantity] = ls
+			}
+		}
+		if len(values) > 0 {
+			plurals[key] = writer.QuantityString{Key: plural.Key, Values: values}
+		}
+	}
+
 	model := &writer.LocalizationModel{
 		Headers: sheet.Headers,
 		Groups:  make([]writer.Group, 0),
-		Plurals: &sheet.Plurals,
+		Plurals: &plurals,
 	}
 
 	var group writer.Group
 	for _, ls := range sheet.Data {
+		if isInfoPlist(tag, ls.Key) || !ls.ExportsTo(tag) {
+			continue
+		}
 		if group.Name != ls.Key.Group() {
 			if group.Name != "" {
 				model.Groups = append(model.Groups, group)
@@ -245,23 +452,83 @@ func createSheetModel(tag string, sheet *sheet) {
 				Strings: make([]writer.AndroidString, 0),
 			}
 		}
-		var value string
-		if overrideIndex >= 0 && overrideIndex < len(ls.Entries) && ls.Entries[overrideIndex] != "" {
-			value = ls.Entries[overrideIndex]
+
+		raw, flavored := rawValue(ls)
+		var normalized string
+		if flavor != "" && value(ls, tag+"@"+flavor) != "" {
+			normalized, flavored = value(ls, tag+"@"+flavor), true
+		} else if !flavored && value(ls, tag) != "" {
+			normalized = value(ls, tag)
 		} else {
-			value = html.EscapeString(w.Normalize(ls.Value))
+			normalized = w.Normalize(raw, ls.HTML)
 		}
 
-		group.Strings = append(group.Strings, writer.AndroidString{ls.Key.Original(), value, ls.Comment})
+		group.Strings = append(group.Strings, writer.AndroidString{Key: ls.Key.Original(), Value: normalized, Comment: ls.Comment, HTML: ls.HTML, Module: ls.Module, Translatable: ls.Translatable, Flavored: flavored})
 	}
 	if group.Name != "" {
 		model.Groups = append(model.Groups, group)
 	}
 
-	sheet.Model = model
+	return model
+}
+
+// flavors returns all flavors with a `value@<flavor>` column in any sheet. Platform columns like `android@<flavor>` only
+// override a flavor, they don't add one.
+func flavors(sheets []*sheet) []string {
+	prefix := *valueColumnName + "@"
+	seen := make(map[string]bool)
+	var flavors []string
+	overrides := make(map[string]bool)
+	for _, sheet := range sheets {
+		for column := range sheet.Columns {
+			if !strings.HasPrefix(column, prefix) {
+				if strings.Contains(column, "@") {
+					overrides[column] = true
+				}
+				continue
+			}
+			flavor := strings.TrimPrefix(column, prefix)
+			if flavor == "" {
+				log.Printf("Ignoring column %q without a flavor, it would overwrite the files of the main export", column)
+			} else if !seen[flavor] {
+				seen[flavor] = true
+				flavors = append(flavors, flavor)
+			}
+		}
+	}
+	for column := range overrides {
+		if flavor := column[strings.LastIndex(column, "@")+1:]; !seen[flavor] {
+			log.Printf("Ignoring column %q, there is no %q column for its flavor", column, prefix+flavor)
+		}
+	}
+	sort.Strings(flavors)
+	return flavors
+}
+
+// hasOverrides returns true if any string of the flavor's model is overridden
+func hasOverrides(model *writer.LocalizationModel) bool {
+	for _, group := range model.Groups {
+		for _, s := range group.Strings {
+			if s.Flavored {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isInfoPlist returns true if the key is part of the InfoPlist group, which is only exported by the iOS writer
+func isInfoPlist(tag string, key writer.CompositeKey) bool {
+	return key.Group() == ios.InfoPlistGroup && tag != (ios.IOSWriter{}).Tag()
 }
 
 func feedWriter(w writer.Writer, sheet *sheet, wg *sync.WaitGroup) {
 	defer wg.Done()
 	w.Export(sheet.Locale, sheet.Model)
+
+	if flavorExporter, ok := w.(writer.FlavorExporter); ok {
+		for flavor, model := range sheet.FlavorModels {
+			flavorExporter.ExportFlavor(flavor, sheet.Locale, model)
+		}
+	}
 }
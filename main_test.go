@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bleeding182/localization/writer"
+)
+
+func TestInfoPlistOnlyOnIOS(t *testing.T) {
+	key := writer.CompositeKeyOf("infoplist__NSCameraUsageDescription")
+	for tag, want := range map[string]bool{"ios": false, "android": true, "compose": true, "moko": true} {
+		if got := isInfoPlist(tag, key); got != want {
+			t.Errorf("isInfoPlist(%q) = %v, want %v", tag, got, want)
+		}
+	}
+	if isInfoPlist("android", writer.CompositeKeyOf("greeting_hello")) {
+		t.Error("other groups must be exported")
+	}
+}
+
+func TestCreateSheetModelSkipsInfoPlist(t *testing.T) {
+	plural := writer.LocalizedString{Key: writer.CompositeKeyOf("infoplist__photos__pl_other"), Value: "%d photos"}
+	s := &sheet{
+		Columns: map[string]int{},
+		Data: []writer.LocalizedString{
+			{Key: writer.CompositeKeyOf("greeting_hello"), Value: "Hello"},
+			{Key: writer.CompositeKeyOf("infoplist__NSCameraUsageDescription"), Value: "Camera"},
+			plural,
+		},
+		Plurals: map[string]writer.QuantityString{"infoplist__photos": {Key: "infoplist__photos", Values: map[writer.Quantity]writer.LocalizedString{writer.QuantityOf("other"): plural}}},
+	}
+	s.Model = createSheetModel("android", s, "")
+	if len(s.Model.Groups) != 1 || s.Model.Groups[0].Name != "greeting" || len(*s.Model.Plurals) != 0 {
+		t.Errorf("the InfoPlist group should be skipped on android, got %v and %v", s.Model.Groups, *s.Model.Plurals)
+	}
+	s.Model = createSheetModel("ios", s, "")
+	if len(s.Model.Groups) != 2 || len(*s.Model.Plurals) != 1 {
+		t.Errorf("the InfoPlist group should be exported on iOS, got %v and %v", s.Model.Groups, *s.Model.Plurals)
+	}
+}
+
+func TestInheritDefaults(t *testing.T) {
+	base := &sheet{
+		Locale: writer.Locale{Name: writer.DefaultLocale},
+		Data: []writer.LocalizedString{
+			{Key: writer.CompositeKeyOf("checkout_pay"), Module: "Checkout", Platforms: []string{"!ios"}},
+			{Key: writer.CompositeKeyOf("checkout_items__pl_other"), Module: "Checkout"},
+		},
+		Plurals: map[string]writer.QuantityString{},
+	}
+	other := writer.LocalizedString{Key: writer.CompositeKeyOf("checkout_items__pl_other"), Module: "Other"}
+	de := &sheet{
+		Locale:  writer.Locale{Name: "de", Language: "de"},
+		Data:    []writer.LocalizedString{{Key: writer.CompositeKeyOf("checkout_pay")}, other},
+		Plurals: map[string]writer.QuantityString{"checkout_items": {Key: "checkout_items", Values: map[writer.Quantity]writer.LocalizedString{writer.QuantityOf("other"): other}}},
+	}
+	inheritDefaults([]*sheet{de, base})
+	if de.Data[0].Module != "Checkout" || de.Data[1].Module != "Checkout" {
+		t.Errorf("strings should use the modules of the default sheet, got %+v", de.Data)
+	}
+	if de.Data[0].ExportsTo("ios") {
+		t.Errorf("strings should use the platforms of the default sheet, got %v", de.Data[0].Platforms)
+	}
+	if module := de.Plurals["checkout_items"].Module(); module != "Checkout" {
+		t.Errorf("plurals should use the modules of the default sheet, got %q", module)
+	}
+}
+
+func TestFlavors(t *testing.T) {
+	value := "value"
+	valueColumnName = &value
+	sheets := []*sheet{
+		{Columns: map[string]int{"key": 0, "value": 1, "value@brandA": 2, "android@brandB": 3}},
+		{Columns: map[string]int{"key": 0, "value": 1, "value@": 2, "ios@brandA": 3}},
+	}
+	if got := flavors(sheets); len(got) != 1 || got[0] != "brandA" {
+		t.Errorf("only value@<flavor> columns should add a flavor, got %v", got)
+	}
+}
+
+func TestPrintOverflows(t *testing.T) {
+	parseArgs(t, "android")
+	parse := func(title string, rows ...[]interface{}) *sheet {
+		locale, err := parseLocale(title)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sheets := make(chan *sheet, 1)
+		parseEntrySetToSheet(&EntrySet{Locale: title, Headers: row("key", "value", "maxLength", "html", "value@brandA"), Values: rows}, locale, sheets)
+		return <-sheets
+	}
+
+	base := parse("default",
+		row("button_confirm", "Confirm", "10"),
+		row("button_link", "<b>Go</b> on", "5", "yes"), // markup isn't counted
+		row("greeting_hello", "Hello, %1$s!", "15", "", "Hi %1$s"),
+		row("greeting_long", "A string without a max length"),
+	)
+	de := parse("de",
+		row("button_confirm", "Bestätigen!"),
+		row("button_link", "<b>Los</b>", "", "yes"),
+		row("greeting_hello", "Hallo", "", "", "Hallo und willkommen, %1$s!"),
+		row("greeting_long", "Ein langer Text ohne maximale Länge"),
+	)
+	fr := parse("fr", row("button_confirm", "Confirmer"))
+
+	var out bytes.Buffer
+	printOverflows(&out, []*sheet{fr, de, base})
+	want := `default: 1 strings are too long
+    greeting_hello (row 4): 18 of 15 characters, "Hello, XXXXXXXXXX!"
+de: 2 strings are too long
+    button_confirm (row 2): 11 of 10 characters, "Bestätigen!"
+    greeting_hello@brandA (row 4): 33 of 15 characters, "Hallo und willkommen, XXXXXXXXXX!"
+`
+	if got := out.String(); got != want {
+		t.Errorf("printOverflows() =\n%v\nwant\n%v", got, want)
+	}
+}
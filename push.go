@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/api/sheets/v4"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const pushCommand = "push"
+
+var pushFiles *[]string
+var dryRun *bool
+
+func RegisterPushCommand(app *kingpin.Application) {
+	command := app.Command(pushCommand, "Push the strings of CSV files, e.g. from an import, to the sheet with the same name as the file. New keys are appended and empty cells filled, but existing values are never overwritten. This requires write access to your spreadsheet.")
+	dryRun = command.Flag("dryRun", "Only print the changes, without writing them to the sheet.").Bool()
+	pushFiles = command.Arg("csv", "The CSV files to push, with the key in the first column and the sheet title as file name, e.g. de.csv.").Required().ExistingFiles()
+}
+
+// Push writes the new and missing strings of all files to their sheets
+func Push() {
+	if err := push(context.Background(), newService(writeScope), *pushFiles, *dryRun); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func push(ctx context.Context, srv *sheets.Service, files []string, dryRun bool) error {
+	for _, file := range files {
+		title := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		rows, err := readCSV(file)
+		if err != nil {
+			return fmt.Errorf("unable to read %v: %v", file, err)
+		}
+
+		var tab *sheets.ValueRange
+		err = retry(ctx, fmt.Sprintf("reading sheet %q", title), func() (err error) {
+			tab, err = srv.Spreadsheets.Values.Get(*sheetID, quoteTitle(title)).Context(ctx).Do()
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("unable to read sheet %q, it has to exist before pushing %v: %v", title, file, err)
+		}
+
+		diff, err := diffSheet(title, tab.Values, rows)
+		if err != nil {
+			return err
+		}
+		diff.print(dryRun)
+		if dryRun || len(diff.updates) == 0 {
+			continue
+		}
+
+		err = retry(ctx, fmt.Sprintf("updating sheet %q", title), func() error {
+			_, err := srv.Spreadsheets.Values.BatchUpdate(*sheetID, &sheets.BatchUpdateValuesRequest{
+				ValueInputOption: "RAW", // values like "=sum" or "100%" must not be parsed
+				Data:             diff.updates,
+			}).Context(ctx).Do()
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("unable to update sheet %q: %v", title, err)
+		}
+	}
+	return nil
+}
+
+func readCSV(file string) ([][]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("missing the header row")
+	}
+	return rows, nil
+}
+
+// sheetDiff are the changes required to add the rows of a CSV to a sheet
+type sheetDiff struct {
+	title     string
+	updates   []*sheets.ValueRange
+	changes   []string // description of every update
+	conflicts []string // existing values that differ, which will never be overwritten
+}
+
+// diffSheet compares the rows of a CSV to the values of the sheet, matching rows by key and columns by their header.
+// Columns missing in the sheet are added, as long as they contain any value.
+func diffSheet(title string, values [][]interface{}, rows [][]string) (*sheetDiff, error) {
+	diff := &sheetDiff{title: title}
+
+	var header []string
+	if len(values) > 0 {
+		for _, cell := range values[0] {
+			header = append(header, fmt.Sprint(cell))
+		}
+	}
+	cell := func(row, column int) string {
+		if row < len(values) && column < len(values[row]) {
+			return fmt.Sprint(values[row][column])
+		}
+		return ""
+	}
+
+	columns := make([]int, len(rows[0]))
+	for i, name := range rows[0] {
+		columns[i] = indexOf(header, name)
+		if columns[i] >= 0 || !hasValues(rows[1:], i) {
+			continue
+		}
+		columns[i] = len(header)
+		header = append(header, name)
+		diff.update(len(header)-1, 0, []interface{}{name}, fmt.Sprintf("add column %q", name))
+	}
+
+	keyColumn, sheetKeyColumn := indexOf(rows[0], *keyColumnName), indexOf(header, *keyColumnName)
+	if keyColumn < 0 || sheetKeyColumn < 0 {
+		return nil, fmt.Errorf("both the CSV and sheet %q need a %q column", title, *keyColumnName)
+	}
+	keys := make(map[string]int)
+	for r := len(values) - 1; r > 0; r-- {
+		if key := cell(r, sheetKeyColumn); key != "" {
+			keys[key] = r
+		}
+	}
+
+	next := len(values)
+	if next == 0 {
+		next = 1 // below the header
+	}
+	for _, row := range rows[1:] {
+		key := row[keyColumn]
+		if key == "" {
+			continue
+		}
+
+		r, ok := keys[key]
+		if !ok {
+			appended := make([]interface{}, len(header))
+			for i := range appended {
+				appended[i] = ""
+			}
+			for i, value := range row {
+				if columns[i] >= 0 {
+					appended[columns[i]] = value
+				}
+			}
+			diff.update(0, next, appended, fmt.Sprintf("add %q", key))
+			keys[key] = next
+			next++
+			continue
+		}
+
+		for i, value := range row {
+			if value == "" || columns[i] < 0 || i == keyColumn {
+				continue
+			}
+			existing := cell(r, columns[i])
+			switch {
+			case existing == "":
+				diff.update(columns[i], r, []interface{}{value}, fmt.Sprintf("set %v of %q to %q", rows[0][i], key, value))
+			case existing != value:
+				diff.conflicts = append(diff.conflicts, fmt.Sprintf("%v of %q in row %d is %q, not %q", rows[0][i], key, r+1, existing, value))
+			}
+		}
+	}
+	return diff, nil
+}
+
+// update adds the values starting at the 0-based column and row
+func (diff *sheetDiff) update(column, row int, values []interface{}, change string) {
+	cells := fmt.Sprintf("%v%d", columnName(column), row+1)
+	if len(values) > 1 {
+		cells += fmt.Sprintf(":%v%d", columnName(column+len(values)-1), row+1)
+	}
+	diff.updates = append(diff.updates, &sheets.ValueRange{
+		Range:  quoteTitle(diff.title) + "!" + cells,
+		Values: [][]interface{}{values},
+	})
+	diff.changes = append(diff.changes, fmt.Sprintf("%v: %v", cells, change))
+}
+
+func (diff *sheetDiff) print(dryRun bool) {
+	action := "Updating"
+	if dryRun {
+		action = "Would update"
+	}
+	fmt.Printf("%v %q: %d changes, %d conflicts\n", action, diff.title, len(diff.changes), len(diff.conflicts))
+	for _, change := range diff.changes {
+		fmt.Printf("    %v\n", change)
+	}
+	for _, conflict := range diff.conflicts {
+		fmt.Printf("    Not overwriting %v\n", conflict)
+	}
+}
+
+// columnName returns the A1 notation of the 0-based column, e.g. A, Z or AA
+func columnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasValues(rows [][]string, column int) bool {
+	for _, row := range rows {
+		if column < len(row) && row[column] != "" {
+			return true
+		}
+	}
+	return false
+}
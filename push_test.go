@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestColumnName(t *testing.T) {
+	for column, want := range map[int]string{0: "A", 1: "B", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(column); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", column, got, want)
+		}
+	}
+}
+
+// updates returns the ranges and values of all updates of the diff
+func updates(diff *sheetDiff) map[string][]interface{} {
+	updates := make(map[string][]interface{})
+	for _, update := range diff.updates {
+		updates[update.Range] = update.Values[0]
+	}
+	return updates
+}
+
+func TestDiffSheet(t *testing.T) {
+	parseArgs(t, "android") // the global flags like --key
+
+	values := [][]interface{}{
+		row("key", "value", "comment"),
+		row("greeting_hello", "Hallo", ""),
+		row("greeting_bye", "", "Leaving"),
+		row("greeting_title", "Titel"),
+	}
+	rows := [][]string{
+		{"key", "value", "comment", "module"},
+		{"greeting_hello", "Hallo", "Greeting", ""},
+		{"greeting_bye", "Tschüss", "Leaving", ""},
+		{"greeting_title", "Überschrift", "", ""},
+		{"greeting_new", "Neu", "", "Greetings"},
+		{"", "ignored", "", ""},
+	}
+
+	diff, err := diffSheet("de", values, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]interface{}{
+		"'de'!D1":    row("module"),                               // new column with values
+		"'de'!C2":    row("Greeting"),                             // fill an empty cell
+		"'de'!B3":    row("Tschüss"),                              // fill an empty cell
+		"'de'!A5:D5": row("greeting_new", "Neu", "", "Greetings"), // append a missing key
+	}
+	if got := updates(diff); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffSheet() updates = %v, want %v", got, want)
+	}
+	if len(diff.conflicts) != 1 || !strings.Contains(diff.conflicts[0], `"Titel", not "Überschrift"`) {
+		t.Errorf("diffSheet() should report the changed title as conflict, got %v", diff.conflicts)
+	}
+}
+
+func TestDiffEmptySheet(t *testing.T) {
+	parseArgs(t, "android") // the global flags like --key
+
+	rows := [][]string{
+		{"key", "value", "comment"},
+		{"greeting_hello", "Hallo", ""},
+		{"greeting_bye", "Tschüss", ""},
+	}
+	diff, err := diffSheet("de", nil, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]interface{}{
+		"'de'!A1":    row("key"),
+		"'de'!B1":    row("value"),
+		"'de'!A2:B2": row("greeting_hello", "Hallo"),
+		"'de'!A3:B3": row("greeting_bye", "Tschüss"),
+	}
+	if got := updates(diff); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffSheet() updates = %v, want %v", got, want)
+	}
+	if len(diff.conflicts) != 0 {
+		t.Errorf("diffSheet() should not report conflicts, got %v", diff.conflicts)
+	}
+}
+
+func TestDiffSheetWithoutKey(t *testing.T) {
+	parseArgs(t, "android") // the global flags like --key
+
+	if _, err := diffSheet("de", [][]interface{}{row("value")}, [][]string{{"value"}, {"Hallo"}}); err == nil {
+		t.Error("diffSheet() should fail without a key column")
+	}
+}
+
+// fakeSheets serves the values of its sheets and records all batch updates.
+// The first requests fail with the status codes of failures.
+type fakeSheets struct {
+	sync.Mutex
+	values   map[string][][]interface{}
+	updates  []*sheets.BatchUpdateValuesRequest
+	failures []int
+	requests []string
+}
+
+func (fake *fakeSheets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.Lock()
+	defer fake.Unlock()
+
+	fake.requests = append(fake.requests, r.URL.Path)
+	if len(fake.failures) > 0 {
+		code := fake.failures[0]
+		fake.failures = fake.failures[1:]
+		http.Error(w, fmt.Sprintf(`{"error": {"code": %d, "message": "failure"}}`, code), code)
+		return
+	}
+
+	const prefix = "/v4/spreadsheets/abc/values"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == prefix+":batchGet":
+		response := &sheets.BatchGetValuesResponse{}
+		for _, title := range r.URL.Query()["ranges"] {
+			values, ok := fake.values[strings.Trim(title, "'")]
+			if !ok {
+				http.Error(w, `{"error": {"code": 400, "message": "Unable to parse range"}}`, http.StatusBadRequest)
+				return
+			}
+			response.ValueRanges = append(response.ValueRanges, &sheets.ValueRange{Range: title, Values: values})
+		}
+		json.NewEncoder(w).Encode(response)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, prefix+"/"):
+		title := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix+"/"), "'")
+		values, ok := fake.values[title]
+		if !ok {
+			http.Error(w, `{"error": {"code": 400, "message": "Unable to parse range"}}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(&sheets.ValueRange{Range: title, Values: values})
+	case r.Method == http.MethodPost && r.URL.Path == prefix+":batchUpdate":
+		request := &sheets.BatchUpdateValuesRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fake.updates = append(fake.updates, request)
+		json.NewEncoder(w).Encode(&sheets.BatchUpdateValuesResponse{})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// pushTest writes de.csv and returns a service using the fake server
+func pushTest(t *testing.T, fake *fakeSheets) (*sheets.Service, string, func()) {
+	folder, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(folder, "de.csv")
+	csv := "key,value\ngreeting_hello,Hallo\ngreeting_new,=sum 100%\n"
+	if err := ioutil.WriteFile(file, []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, done := fakeService(t, fake)
+	return srv, file, func() {
+		done()
+		os.RemoveAll(folder)
+	}
+}
+
+func TestPushDryRun(t *testing.T) {
+	fake := &fakeSheets{values: map[string][][]interface{}{"de": {row("key", "value"), row("greeting_hello", "")}}}
+	srv, file, done := pushTest(t, fake)
+	defer done()
+	parseArgs(t, "android")
+
+	if err := push(context.Background(), srv, []string{file}, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.updates) != 0 {
+		t.Errorf("a dry run must not update the sheet, got %v", fake.updates)
+	}
+}
+
+func TestPush(t *testing.T) {
+	fake := &fakeSheets{values: map[string][][]interface{}{"de": {row("key", "value"), row("greeting_hello", "")}}}
+	srv, file, done := pushTest(t, fake)
+	defer done()
+	parseArgs(t, "android")
+
+	if err := push(context.Background(), srv, []string{file}, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.updates) != 1 {
+		t.Fatalf("expected a single batch update, got %v", fake.updates)
+	}
+	request := fake.updates[0]
+	if request.ValueInputOption != "RAW" {
+		t.Errorf("values must not be parsed, got ValueInputOption %q", request.ValueInputOption)
+	}
+	got := make(map[string][]interface{})
+	for _, update := range request.Data {
+		got[update.Range] = update.Values[0]
+	}
+	want := map[string][]interface{}{
+		"'de'!B2":    row("Hallo"),
+		"'de'!A3:B3": row("greeting_new", "=sum 100%"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("push() updates = %v, want %v", got, want)
+	}
+}
+
+func TestPushMissingSheet(t *testing.T) {
+	fake := &fakeSheets{values: map[string][][]interface{}{}}
+	srv, file, done := pushTest(t, fake)
+	defer done()
+	parseArgs(t, "android")
+
+	if err := push(context.Background(), srv, []string{file}, false); err == nil || !strings.Contains(err.Error(), "has to exist") {
+		t.Errorf("push() should fail for a missing sheet, got %v", err)
+	}
+}
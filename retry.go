@@ -0,0 +1,48 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	maxAttempts = 6
+	maxBackoff  = 32 * time.Second
+)
+
+// initialBackoff is the wait before the first retry, doubled for every further attempt
+var initialBackoff = time.Second
+
+// retry calls do until it succeeds, retrying rate limits (429) and server errors (5xx) with an exponential backoff and jitter.
+// Any other error, or the last one once all attempts were used, is returned.
+func retry(ctx context.Context, description string, do func() error) error {
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := do()
+		if err == nil || !retryable(err) || attempt == maxAttempts {
+			return err
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		log.Printf("Warning: %v failed (attempt %d of %d), retrying in %v. %v", description, attempt, maxAttempts, wait.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func retryable(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	return ok && (apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError)
+}
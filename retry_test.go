@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/sheets/v4"
+)
+
+// fakeService returns a service using a fake server and shortens the backoff of retries
+func fakeService(t *testing.T, fake *fakeSheets) (*sheets.Service, func()) {
+	backoff := initialBackoff
+	initialBackoff = time.Millisecond
+
+	server := httptest.NewServer(fake)
+	srv, err := sheets.New(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.BasePath = server.URL + "/"
+	return srv, func() {
+		server.Close()
+		initialBackoff = backoff
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := map[error]bool{
+		&googleapi.Error{Code: http.StatusTooManyRequests}:     true,
+		&googleapi.Error{Code: http.StatusInternalServerError}: true,
+		&googleapi.Error{Code: http.StatusServiceUnavailable}:  true,
+		&googleapi.Error{Code: http.StatusBadRequest}:          false,
+		&googleapi.Error{Code: http.StatusForbidden}:           false,
+		context.Canceled: false,
+	}
+	for err, want := range tests {
+		if got := retryable(err); got != want {
+			t.Errorf("retryable(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	fake := &fakeSheets{
+		values:   map[string][][]interface{}{"de": {row("key", "value")}},
+		failures: []int{http.StatusTooManyRequests, http.StatusInternalServerError},
+	}
+	srv, done := fakeService(t, fake)
+	defer done()
+
+	var tab *sheets.ValueRange
+	err := retry(context.Background(), "reading de", func() (err error) {
+		tab, err = srv.Spreadsheets.Values.Get("abc", "'de'").Do()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.requests) != 3 {
+		t.Errorf("expected two retries, got %d requests", len(fake.requests))
+	}
+	if !reflect.DeepEqual(tab.Values, [][]interface{}{row("key", "value")}) {
+		t.Errorf("got values %v", tab.Values)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	fake := &fakeSheets{failures: []int{400, 500, 500, 500, 500, 500, 500, 500}}
+	srv, done := fakeService(t, fake)
+	defer done()
+
+	get := func() error {
+		_, err := srv.Spreadsheets.Values.Get("abc", "'de'").Do()
+		return err
+	}
+	if err := retry(context.Background(), "reading de", get); err == nil || len(fake.requests) != 1 {
+		t.Errorf("a bad request must not be retried, got %v after %d requests", err, len(fake.requests))
+	}
+	fake.requests = nil
+	if err := retry(context.Background(), "reading de", get); err == nil || len(fake.requests) != maxAttempts {
+		t.Errorf("expected an error after %d attempts, got %v after %d requests", maxAttempts, err, len(fake.requests))
+	}
+}
+
+func TestReadData(t *testing.T) {
+	parseArgs(t, "android")
+	tabs := []*sheets.SheetProperties{{Title: "default"}, {Title: "de"}}
+	read := func(fake *fakeSheets) ([]*sheetData, []error) {
+		srv, done := fakeService(t, fake)
+		defer done()
+		data := make([]*sheetData, len(tabs))
+		errs := make([]error, len(tabs))
+		readData(context.Background(), srv, readValues, tabs, data, errs)
+		return data, errs
+	}
+
+	fake := &fakeSheets{values: map[string][][]interface{}{"default": {row("key")}, "de": {row("key")}}, failures: []int{503}}
+	data, errs := read(fake)
+	if errs[0] != nil || errs[1] != nil || len(data[1].values) != 1 || len(fake.requests) != 2 {
+		t.Errorf("expected a retried batchGet, got %v after %v", errs, fake.requests)
+	}
+
+	// the missing sheet is found by reading the sheets one by one
+	fake = &fakeSheets{values: map[string][][]interface{}{"default": {row("key")}}}
+	data, errs = read(fake)
+	if errs[0] != nil || errs[1] == nil || len(data[0].values) != 1 {
+		t.Errorf("expected an error for de only, got %v", errs)
+	}
+	if len(fake.requests) != 3 {
+		t.Errorf("expected a batchGet and two single reads, got %v", fake.requests)
+	}
+
+	// rate limits aren't worked around by sending more requests
+	failures := make([]int, maxAttempts)
+	for i := range failures {
+		failures[i] = http.StatusTooManyRequests
+	}
+	fake = &fakeSheets{values: map[string][][]interface{}{"default": {row("key")}, "de": {row("key")}}, failures: failures}
+	_, errs = read(fake)
+	if errs[0] == nil || errs[1] == nil || len(fake.requests) != maxAttempts {
+		t.Errorf("expected the rate limit for both sheets without further requests, got %v after %d requests", errs, len(fake.requests))
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"os/user"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
@@ -19,8 +21,12 @@ import (
 )
 
 type sheetData struct {
-	sheet   *sheets.ValueRange
-	sheetID string
+	values   [][]interface{}
+	notes    [][]string // notes of the cells of every row, only read with --gridData
+	excluded []bool     // rows with the --excludeFormat, only read with --gridData
+	sheetID  string
+	title    string
+	glossary bool // the --glossary sheet, which is not a locale
 }
 
 var clientIDJson = []byte(`
@@ -40,60 +46,233 @@ var clientIDJson = []byte(`
 	}
 `)
 
-func loadSpreadSheet() ([]*sheetData, error) {
+const (
+	readonlyScope = "https://www.googleapis.com/auth/spreadsheets.readonly"
+	writeScope    = "https://www.googleapis.com/auth/spreadsheets"
+)
+
+// tokenCaches of the scopes, so the export never requires write access
+var tokenCaches = map[string]string{
+	readonlyScope: "sheets.googleapis.com-github-bleeding182-localization.json",
+	writeScope:    "sheets.googleapis.com-github-bleeding182-localization-write.json",
+}
+
+// newService returns an authorized client for the scope
+func newService(scope string) *sheets.Service {
 	ctx := context.Background()
 
-	// If modifying these scopes, delete your previously saved credentials
-	// at ~/.credentials/sheets.googleapis.com-go-quickstart.json
-	config, err := google.ConfigFromJSON(clientIDJson, "https://www.googleapis.com/auth/spreadsheets.readonly")
+	// If modifying these scopes, delete your previously saved credentials in ~/.credentials
+	config, err := google.ConfigFromJSON(clientIDJson, scope)
 	if err != nil {
 		log.Fatalf("Unable to parse client secret file to config: %v", err)
-		panic(err)
 	}
-	client := getClient(ctx, config)
+	client := getClient(ctx, config, tokenCaches[scope])
 
 	srv, err := sheets.New(client)
 	if err != nil {
 		log.Fatalf("Unable to retrieve Sheets Client %v", err)
-		panic(err)
 	}
+	return srv
+}
+
+// sheetsPerRequest limits the number of sheets read with a single batchGet
+const sheetsPerRequest = 10
 
-	sheetsInfo, err := srv.Spreadsheets.Get(*sheetID).Do()
+// loadSpreadSheet reads all sheets to export, as well as the --glossary sheet if withGlossary is set
+func loadSpreadSheet(withGlossary bool) ([]*sheetData, error) {
+	ctx := context.Background()
+	srv := newService(readonlyScope)
+
+	var sheetsInfo *sheets.Spreadsheet
+	err := retry(ctx, "reading the sheets", func() (err error) {
+		sheetsInfo, err = srv.Spreadsheets.Get(*sheetID).Context(ctx).Do()
+		return err
+	})
 	if err != nil {
-		log.Fatalf("Unable to read sheets information: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("unable to read sheets information: %v", err)
 	}
 
-	results := make(chan *sheetData)
+	var tabs []*sheets.SheetProperties
+	var glossaryTab *sheets.SheetProperties
 	for _, sheet := range sheetsInfo.Sheets {
-		readRange := fmt.Sprintf("%v!A:M", sheet.Properties.Title)
-		go readData(results, srv, readRange, strconv.FormatInt(sheet.Properties.SheetId, 10))
+		switch {
+		case !includeSheet(sheet.Properties):
+		case sheet.Properties.Title == *glossaryTitle:
+			glossaryTab = sheet.Properties
+		default:
+			tabs = append(tabs, sheet.Properties)
+		}
+	}
+	if len(tabs) == 0 {
+		return nil, fmt.Errorf("no sheets to export")
+	}
+
+	var invalid []string
+	for _, tab := range tabs {
+		if _, err := parseLocale(tab.Title); err != nil {
+			invalid = append(invalid, err.Error())
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%v\nSkip these sheets with --excludeSheet or --ignorePattern, or map them to a locale with --localeAlias", strings.Join(invalid, "\n"))
 	}
 
-	sheetData := make([]*sheetData, 0, len(sheetsInfo.Sheets))
+	if withGlossary {
+		if glossaryTab == nil {
+			return nil, fmt.Errorf("no glossary sheet %q, change its title with --glossary or include it with --sheet", *glossaryTitle)
+		}
+		tabs = append(tabs, glossaryTab)
+	}
 
-	for r := range results {
-		sheetData = append(sheetData, r)
-		if len(sheetData) == cap(sheetData) {
-			close(results)
+	if *concurrency < 1 {
+		return nil, fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	read := readValues
+	if *gridData {
+		if err := parseExcludeFormat(); err != nil {
+			return nil, err
 		}
+		read = readGridData
 	}
 
-	return sheetData, nil
+	// read the sheets in batches, with at most --concurrency requests at a time
+	data := make([]*sheetData, len(tabs))
+	errs := make([]error, len(tabs))
+	limit := make(chan bool, *concurrency)
+	wg := sync.WaitGroup{}
+	for start := 0; start < len(tabs); start += sheetsPerRequest {
+		end := start + sheetsPerRequest
+		if end > len(tabs) {
+			end = len(tabs)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			limit <- true
+			defer func() { <-limit }()
+			readData(ctx, srv, read, tabs[start:end], data[start:end], errs[start:end])
+		}(start, end)
+	}
+	wg.Wait()
+
+	result := make([]*sheetData, 0, len(tabs))
+	for i, tab := range tabs {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("unable to read sheet %q: %v", tab.Title, errs[i])
+		}
+		if len(data[i].values) == 0 {
+			log.Printf("Warning: skipping sheet %q, it is empty", tab.Title)
+			continue
+		}
+		data[i].sheetID = strconv.FormatInt(tab.SheetId, 10)
+		data[i].title = tab.Title
+		data[i].glossary = tab == glossaryTab
+		result = append(result, data[i])
+	}
+	return result, nil
 }
 
-func readData(results chan *sheetData, srv *sheets.Service, readRange string, sheetId string) {
-	res, err := srv.Spreadsheets.Values.Get(*sheetID, readRange).Do()
+// readFunc reads the data of the tabs with a single request
+type readFunc func(ctx context.Context, srv *sheets.Service, tabs []*sheets.SheetProperties) ([]*sheetData, error)
+
+// readData reads the tabs with a single request. If that fails for a reason other than rate limits or server errors,
+// every tab is read on its own to find the one causing the error.
+func readData(ctx context.Context, srv *sheets.Service, read readFunc, tabs []*sheets.SheetProperties, data []*sheetData, errs []error) {
+	titles := make([]string, len(tabs))
+	for i, tab := range tabs {
+		titles[i] = quoteTitle(tab.Title)
+	}
+
+	var res []*sheetData
+	err := retry(ctx, "reading "+strings.Join(titles, ", "), func() (err error) {
+		res, err = read(ctx, srv, tabs)
+		return err
+	})
+	if err == nil {
+		copy(data, res)
+		return
+	}
+	if len(tabs) == 1 || retryable(err) {
+		// reading the tabs one by one would only run into the same rate limit or server error
+		for i := range errs {
+			errs[i] = err
+		}
+		return
+	}
+
+	for i := range tabs {
+		errs[i] = retry(ctx, "reading "+titles[i], func() (err error) {
+			res, err = read(ctx, srv, tabs[i:i+1])
+			return err
+		})
+		if errs[i] != nil {
+			return
+		}
+		data[i] = res[0]
+	}
+}
+
+// readValues reads the formatted values of the tabs
+func readValues(ctx context.Context, srv *sheets.Service, tabs []*sheets.SheetProperties) ([]*sheetData, error) {
+	ranges := make([]string, len(tabs))
+	for i, tab := range tabs {
+		ranges[i] = sheetRange(tab.Title)
+	}
+	res, err := srv.Spreadsheets.Values.BatchGet(*sheetID).Ranges(ranges...).Context(ctx).Do()
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	data := make([]*sheetData, len(tabs))
+	for i, values := range res.ValueRanges {
+		data[i] = &sheetData{values: values.Values}
 	}
-	results <- &sheetData{res, sheetId}
+	return data, nil
+}
+
+// includeSheet returns false for sheets filtered by --sheet or --excludeSheet, using their title or gid, as well as hidden sheets
+// and sheets matching the --ignorePattern, unless they were included explicitly
+func includeSheet(tab *sheets.SheetProperties) bool {
+	matches := func(filter []string) bool {
+		gid := strconv.FormatInt(tab.SheetId, 10)
+		for _, f := range filter {
+			if f == tab.Title || f == gid {
+				return true
+			}
+		}
+		return false
+	}
+	if matches(*excludeSheets) {
+		return false
+	}
+	if len(*includeSheets) > 0 {
+		return matches(*includeSheets)
+	}
+	if tab.Hidden && !*includeHidden {
+		return false
+	}
+	// an empty pattern would match every title
+	return (*ignorePattern).String() == "" || !(*ignorePattern).MatchString(tab.Title)
+}
+
+// sheetRange returns the A1 range of the sheet to read, either --range or all of its cells
+func sheetRange(title string) string {
+	if *sheetRangeLimit == "" {
+		return quoteTitle(title)
+	}
+	return quoteTitle(title) + "!" + *sheetRangeLimit
+}
+
+// quoteTitle quotes the sheet title for A1 ranges
+func quoteTitle(title string) string {
+	return "'" + strings.Replace(title, "'", "''", -1) + "'"
 }
 
 // getClient uses a Context and Config to retrieve a Token
 // then generate a Client. It returns the generated Client.
-func getClient(ctx context.Context, config *oauth2.Config) *http.Client {
-	cacheFile, err := tokenCacheFile()
+func getClient(ctx context.Context, config *oauth2.Config, cacheName string) *http.Client {
+	cacheFile, err := tokenCacheFile(cacheName)
 	if err != nil {
 		log.Fatalf("Unable to get path to cached credential file. %v", err)
 	}
@@ -126,15 +305,14 @@ func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
 
 // tokenCacheFile generates credential file path/filename.
 // It returns the generated credential path/filename.
-func tokenCacheFile() (string, error) {
+func tokenCacheFile(name string) (string, error) {
 	usr, err := user.Current()
 	if err != nil {
 		return "", err
 	}
 	tokenCacheDir := filepath.Join(usr.HomeDir, ".credentials")
 	os.MkdirAll(tokenCacheDir, 0700)
-	return filepath.Join(tokenCacheDir,
-		url.QueryEscape("sheets.googleapis.com-github-bleeding182-localization.json")), err
+	return filepath.Join(tokenCacheDir, url.QueryEscape(name)), err
 }
 
 // tokenFromFile retrieves a Token from a given file path.
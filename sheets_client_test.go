@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestIncludeSheet(t *testing.T) {
+	tabs := map[string]*sheets.SheetProperties{
+		"default": {Title: "default", SheetId: 0},
+		"de":      {Title: "de", SheetId: 123},
+		"hidden":  {Title: "fr", SheetId: 456, Hidden: true},
+		"readme":  {Title: "_README", SheetId: 789},
+	}
+	tests := []struct {
+		args     []string
+		included []string
+	}{
+		{nil, []string{"default", "de"}},
+		{[]string{"--excludeSheet", "123"}, []string{"default"}},
+		{[]string{"--sheet", "de", "--sheet", "456"}, []string{"de", "hidden"}},
+		{[]string{"--sheet", "de", "--excludeSheet", "de"}, nil},
+		{[]string{"--includeHidden"}, []string{"default", "de", "hidden"}},
+		{[]string{"--ignorePattern", ""}, []string{"default", "de", "readme"}},
+		{[]string{"--ignorePattern", "^d"}, []string{"readme"}},
+	}
+	for _, test := range tests {
+		parseArgs(t, append([]string{"android"}, test.args...)...)
+		expected := make(map[string]bool)
+		for _, name := range test.included {
+			expected[name] = true
+		}
+		for name, tab := range tabs {
+			if got := includeSheet(tab); got != expected[name] {
+				t.Errorf("%v: includeSheet(%v) = %v, want %v", test.args, name, got, expected[name])
+			}
+		}
+	}
+}
+
+func TestSheetRange(t *testing.T) {
+	parseArgs(t, "android")
+	if got := sheetRange("Don't"); got != "'Don''t'" {
+		t.Errorf("sheetRange() = %v, want the quoted title", got)
+	}
+	parseArgs(t, "android", "--range", "A:M")
+	if got := sheetRange("de"); got != "'de'!A:M" {
+		t.Errorf("sheetRange() = %v, want 'de'!A:M", got)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bleeding182/localization/writer"
+)
+
+func defaultSheet(sheets []*sheet) *sheet {
+	for _, sheet := range sheets {
+		if sheet.Locale.IsDefault() {
+			return sheet
+		}
+	}
+	return nil
+}
+
+// removeUntranslatable drops all strings from the locale sheets that are marked as not translatable in the default sheet,
+// warning about any that were overridden anyway.
+func removeUntranslatable(sheets []*sheet) {
+	base := defaultSheet(sheets)
+	if base == nil {
+		return
+	}
+
+	untranslatable := make(map[string]bool)
+	for _, ls := range base.Data {
+		if !ls.Translatable {
+			untranslatable[ls.Key.Original()] = true
+		}
+	}
+	if len(untranslatable) == 0 {
+		return
+	}
+
+	for _, sheet := range sheets {
+		if sheet == base {
+			continue
+		}
+		data := sheet.Data[:0]
+		for _, ls := range sheet.Data {
+			if !untranslatable[ls.Key.Original()] {
+				data = append(data, ls)
+				continue
+			}
+			if ls.Value != "" {
+				log.Printf("Warning: sheet %q overrides %q in row %d, which is not translatable. The value will be ignored.", sheet.Locale.Name, ls.Key.Original(), ls.Row)
+			}
+		}
+		sheet.Data = data
+
+		for key, plural := range sheet.Plurals {
+			for quantity, ls := range plural.Values {
+				if untranslatable[ls.Key.Original()] {
+					delete(plural.Values, quantity)
+				}
+			}
+			if len(plural.Values) == 0 {
+				delete(sheet.Plurals, key)
+			}
+		}
+	}
+}
+
+// inheritDefaults copies the module and platforms of every string in the default sheet to the locale sheets, which usually have neither column.
+// Strings.swift and the resource folders are generated from the default sheet, so all locales have to use its modules and platforms.
+// All quantities of a plural are exported together and have to use the same module.
+func inheritDefaults(sheets []*sheet) {
+	base := defaultSheet(sheets)
+	if base == nil {
+		return
+	}
+
+	defaults := make(map[string]writer.LocalizedString)
+	for _, ls := range base.Data {
+		defaults[ls.Key.Original()] = ls
+	}
+	inherit := func(ls writer.LocalizedString) writer.LocalizedString {
+		if d, ok := defaults[ls.Key.Original()]; ok {
+			ls.Module = d.Module
+			ls.Platforms = d.Platforms
+		}
+		return ls
+	}
+	for _, sheet := range sheets {
+		if sheet == base {
+			continue
+		}
+		for i, ls := range sheet.Data {
+			sheet.Data[i] = inherit(ls)
+		}
+		for _, plural := range sheet.Plurals {
+			for quantity, ls := range plural.Values {
+				plural.Values[quantity] = inherit(ls)
+			}
+		}
+	}
+}
+
+// removeExcluded drops all strings whose rows were excluded by their formatting with --gridData.
+// Rows excluded in the default sheet are dropped from all locales.
+func removeExcluded(sheets []*sheet) {
+	excluded := make(map[string]bool)
+	if base := defaultSheet(sheets); base != nil {
+		for _, ls := range base.Data {
+			if ls.Excluded {
+				excluded[ls.Key.Original()] = true
+			}
+		}
+	}
+
+	for _, sheet := range sheets {
+		count := 0
+		keep := func(ls writer.LocalizedString) bool {
+			if ls.Excluded || excluded[ls.Key.Original()] {
+				count++
+				return false
+			}
+			return true
+		}
+
+		data := sheet.Data[:0]
+		for _, ls := range sheet.Data {
+			if keep(ls) {
+				data = append(data, ls)
+			}
+		}
+		sheet.Data = data
+
+		for key, plural := range sheet.Plurals {
+			for quantity, ls := range plural.Values {
+				if !keep(ls) {
+					delete(plural.Values, quantity)
+				}
+			}
+			if len(plural.Values) == 0 {
+				delete(sheet.Plurals, key)
+			}
+		}
+
+		if count > 0 {
+			log.Printf("Excluded %d strings of sheet %q by their format", count, sheet.Locale.Name)
+		}
+	}
+}
+
+// printOverflows lists all strings whose rendered value, or the value of any flavor, is longer than the max length of their key in the default sheet
+func printOverflows(out io.Writer, sheets []*sheet) {
+	base := defaultSheet(sheets)
+	if base == nil {
+		return
+	}
+
+	maxLengths := make(map[string]int)
+	for _, ls := range base.Data {
+		if ls.MaxLength > 0 {
+			maxLengths[ls.Key.Original()] = ls.MaxLength
+		}
+	}
+	if len(maxLengths) == 0 {
+		return
+	}
+
+	sorted := make([]*sheet, len(sheets))
+	copy(sorted, sheets)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i] == base || sorted[j] == base {
+			return sorted[i] == base
+		}
+		return sorted[i].Locale.Name < sorted[j].Locale.Name
+	})
+
+	for _, sheet := range sorted {
+		// flavors can override the value of a key and have to fit as well
+		var flavors []string
+		for column := range sheet.Columns {
+			if flavor := strings.TrimPrefix(column, *valueColumnName+"@"); flavor != column && flavor != "" {
+				flavors = append(flavors, flavor)
+			}
+		}
+		sort.Strings(flavors)
+
+		var overflows []string
+		for _, ls := range sheet.Data {
+			maxLength, ok := maxLengths[ls.Key.Original()]
+			if !ok {
+				continue
+			}
+			check := func(name, value string) {
+				if value == "" {
+					return
+				}
+				rendered := writer.RenderSample(value)
+				if ls.HTML {
+					rendered = writer.StripMarkup(rendered)
+				}
+				if length := utf8.RuneCountInString(rendered); length > maxLength {
+					overflows = append(overflows, fmt.Sprintf("%v (row %d): %d of %d characters, %q", name, ls.Row, length, maxLength, rendered))
+				}
+			}
+			check(ls.Key.Original(), ls.Value)
+			for _, flavor := range flavors {
+				if index := sheet.columnIndex(*valueColumnName + "@" + flavor); index < len(ls.Entries) {
+					check(ls.Key.Original()+"@"+flavor, ls.Entries[index])
+				}
+			}
+		}
+		if len(overflows) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(out, "%v: %d strings are too long\n", sheet.Locale.Name, len(overflows))
+		for _, overflow := range overflows {
+			fmt.Fprintf(out, "    %v\n", overflow)
+		}
+	}
+}
+
+// printMissingTranslations lists all translatable strings of the default sheet that are missing or empty in a locale
+func printMissingTranslations(sheets []*sheet) {
+	base := defaultSheet(sheets)
+	if base == nil {
+		return
+	}
+
+	for _, sheet := range sheets {
+		if sheet == base {
+			continue
+		}
+		values := make(map[string]string)
+		for _, ls := range sheet.Data {
+			values[ls.Key.Original()] = ls.Value
+		}
+
+		var missing []string
+		for _, ls := range base.Data {
+			if ls.Translatable && values[ls.Key.Original()] == "" {
+				missing = append(missing, ls.Key.Original())
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		sort.Strings(missing)
+
+		fmt.Printf("%v: %d missing translations\n", sheet.Locale.Name, len(missing))
+		for _, key := range missing {
+			fmt.Printf("    %v\n", key)
+		}
+	}
+}
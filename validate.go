@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/bleeding182/localization/writer"
+)
+
+// validate reports duplicate keys, keys that only differ in case and, for writers implementing writer.Validator,
+// keys that generate colliding identifiers or reserved words. Nothing gets exported if any problem is found.
+func validate(command string, sheets []*sheet) {
+	var problems []string
+	for _, sheet := range sheets {
+		problems = append(problems, validateSheet(command, sheet)...)
+	}
+	if len(problems) == 0 {
+		return
+	}
+
+	for _, problem := range problems {
+		log.Print(problem)
+	}
+	log.Fatalf("Found %d problems with the keys, nothing was exported", len(problems))
+}
+
+func validateSheet(command string, sheet *sheet) []string {
+	var problems []string
+	report := func(ls writer.LocalizedString, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("Error: sheet %q, row %d: %v", sheet.Locale.Name, ls.Row, fmt.Sprintf(format, args...)))
+	}
+
+	// report in the order of the sheet, so the first occurrence of a key wins
+	rows := make([]writer.LocalizedString, len(sheet.Data))
+	copy(rows, sheet.Data)
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Row < rows[j].Row
+	})
+
+	keys := make(map[string]writer.LocalizedString)
+	lowercase := make(map[string]writer.LocalizedString)
+	unique := rows[:0:0]
+	for _, ls := range rows {
+		key := ls.Key.Original()
+		if other, ok := keys[key]; ok {
+			report(ls, "duplicate key %q, already used in row %d", key, other.Row)
+			continue
+		}
+		keys[key] = ls
+		unique = append(unique, ls)
+
+		if other, ok := lowercase[strings.ToLower(key)]; ok {
+			report(ls, "key %q only differs in case from %q in row %d", key, other.Key.Original(), other.Row)
+			continue
+		}
+		lowercase[strings.ToLower(key)] = ls
+	}
+
+	// only the modules of the default sheet are used, see inheritDefaults
+	if sheet.Locale.IsDefault() {
+		for _, ls := range unique {
+			if ls.Key.Quantity() == "" {
+				continue
+			}
+			plural := sheet.Plurals[ls.Key.PlainKey()]
+			if module := plural.Module(); ls.Module != module {
+				report(ls, "%q uses module %q, but its plural %q is exported to module %q", ls.Key.Original(), ls.Module, plural.Key, module)
+			}
+		}
+	}
+
+	// only the default sheet generates code, the other sheets use the same keys
+	validator, ok := Writers[command].(writer.Validator)
+	if !ok || !sheet.Locale.IsDefault() {
+		return problems
+	}
+
+	type source struct {
+		writer.LocalizedString
+		source string
+	}
+	identifiers := make(map[string]source)
+	reported := make(map[string]bool)
+	for _, ls := range unique {
+		if !ls.ExportsTo(command) {
+			continue
+		}
+		if err := validator.ValidateKey(ls.Key); err != nil {
+			report(ls, "%v", err)
+		}
+		for _, identifier := range validator.Identifiers(ls.Key) {
+			other, ok := identifiers[identifier.Name]
+			if !ok {
+				identifiers[identifier.Name] = source{ls, identifier.Source}
+				continue
+			}
+			collision := identifier.Source + " " + other.source
+			if other.source != identifier.Source && !reported[collision] {
+				reported[collision] = true
+				report(ls, "%v generates %v, which collides with %v in row %d", identifier.Source, identifier.Name, other.source, other.Row)
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// parseSheet parses the rows of key, value and module like an exported sheet
+func parseSheet(t *testing.T, title string, rows ...[]interface{}) *sheet {
+	locale, err := parseLocale(title)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheets := make(chan *sheet, 1)
+	parseEntrySetToSheet(&EntrySet{Locale: title, Headers: row("key", "value", "module"), Values: rows}, locale, sheets)
+	return <-sheets
+}
+
+func expectProblems(t *testing.T, problems []string, expected ...string) {
+	if len(problems) != len(expected) {
+		t.Errorf("expected %d problems, got %q", len(expected), problems)
+		return
+	}
+	for i, problem := range problems {
+		if !strings.Contains(problem, expected[i]) {
+			t.Errorf("expected %q to contain %q", problem, expected[i])
+		}
+	}
+}
+
+func TestValidateDuplicateKeys(t *testing.T) {
+	command := parseArgs(t, "compose")
+	sheet := parseSheet(t, "de",
+		row("greeting_hello", "Hallo"),
+		row("greeting_hello", "Servus"),
+		row("greeting_Hello", "Hallo"),
+	)
+	expectProblems(t, validateSheet(command, sheet),
+		`row 3: duplicate key "greeting_hello", already used in row 2`,
+		`row 4: key "greeting_Hello" only differs in case from "greeting_hello" in row 2`,
+	)
+}
+
+func TestValidateCollisions(t *testing.T) {
+	command := parseArgs(t, "ios")
+	sheet := parseSheet(t, "default",
+		row("song_line__title", "Title"),
+		row("songLine__title", "Title"),
+		row("self__title", "Self"),
+	)
+	expectProblems(t, validateSheet(command, sheet),
+		"group songLine generates Strings.SongLine, which collides with group song_line in row 2",
+		"key songLine__title generates Strings.SongLine.SongLineTitle, which collides with key song_line__title in row 2",
+		`row 4: "Self" is a reserved word in Swift`,
+	)
+
+	sheet.Locale.Name = "de"
+	sheet.Locale.Language = "de"
+	expectProblems(t, validateSheet(command, sheet))
+}
+
+func TestValidateReservedWords(t *testing.T) {
+	command := parseArgs(t, "android", "--kotlinOutputFolder", "kotlin", "--kotlinPackage", "com.example")
+	sheet := parseSheet(t, "default",
+		row("misc__object", "Object"),
+		row("misc__title", "Title"),
+	)
+	expectProblems(t, validateSheet(command, sheet),
+		`row 2: "object" is a reserved word in Kotlin`,
+	)
+}
+
+func TestValidatePluralModule(t *testing.T) {
+	command := parseArgs(t, "moko")
+	sheet := parseSheet(t, "default",
+		row("cart_items__pl_one", "%d item", "Checkout"),
+		row("cart_items__pl_other", "%d items", "Cart"),
+	)
+	expectProblems(t, validateSheet(command, sheet),
+		`"cart_items__pl_one" uses module "Checkout", but its plural "cart_items" is exported to module "Cart"`,
+	)
+}
+
+func TestValidateInfoPlistPlural(t *testing.T) {
+	command := parseArgs(t, "ios")
+	sheet := parseSheet(t, "default",
+		row("infoplist__NSCameraUsageDescription", "Camera"),
+		row("infoplist__photos__pl_one", "%d photo"),
+		row("infoplist__photos__pl_other", "%d photos"),
+	)
+	expectProblems(t, validateSheet(command, sheet),
+		`"infoplist__photos__pl_one" is a plural, but InfoPlist.strings doesn't support plurals`,
+		`"infoplist__photos__pl_other" is a plural, but InfoPlist.strings doesn't support plurals`,
+	)
+}
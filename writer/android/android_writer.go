@@ -1,20 +1,23 @@
 package android
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/bleeding182/localization/writer"
 
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 )
 
-const androidTemplate = `
-<?xml version=\"1.0\" encoding=\"utf-8\"?>
+const androidTemplate = `<?xml version="1.0" encoding="utf-8"?>
 {{range $header := $.Headers -}}
 <!-- {{$header}} -->
 {{end -}}
@@ -25,7 +28,9 @@ const androidTemplate = `
     {{- if .Comment}}
     <!-- {{.Comment}} -->
     {{- end}}
-    <string name="{{.Key}}">{{.Value}}</string>
+    <string name="{{stringName .Key}}"{{if not .Translatable}} translatable="false"{{end}}{{if unformatted .Value}} formatted="false"{{end}}>
+        {{- if .HTML}}<![CDATA[{{.Value}}]]>{{else}}{{.Value}}{{end -}}
+    </string>
     {{- end}}
     <!-- endregion -->
 {{end}}
@@ -33,7 +38,7 @@ const androidTemplate = `
 {{- range $p := $.Plurals}}
     <plurals name="{{.Key}}">
     {{- range $q, $v := .Values}}
-        <item quantity="{{$q}}">@string/{{$v.Key.Original}}</item>
+        <item quantity="{{$q}}">@string/{{stringName $v.Key.Original}}</item>
     {{- end}}
     </plurals>
     <!-- endregion -->
@@ -41,14 +46,52 @@ const androidTemplate = `
 </resources>
 `
 
+const localesConfigTemplate = `<?xml version="1.0" encoding="utf-8"?>
+<!-- Do _not_ modify -->
+<locale-config xmlns:android="http://schemas.android.com/apk/res/android">
+{{- range $locale := .}}
+    <locale android:name="{{$locale}}" />
+{{- end}}
+</locale-config>
+`
+
+const gradleTemplate = `// Do _not_ modify
+android {
+    defaultConfig {
+        resourceConfigurations += {{if .Kotlin}}listOf({{else}}[{{end}}
+        {{- range $i, $q := .Qualifiers}}{{if $i}}, {{end}}"{{$q}}"{{end}}
+        {{- if .Kotlin}}){{else}}]{{end}}
+    }
+}
+`
+
 const tagAndroid = "android"
 
 var regions *bool
 var outputFolder *string
+var localesConfig *bool
+var defaultLocale, gradleSnippet *string
+var kotlinFolder, kotlinPackage, rPackage *string
+var kotlinModulePackages *map[string]string
+var flavorFolder *string
 
 func (writer AndroidWriter) RegisterCommand(app *kingpin.Application) {
 	command := app.Command(tagAndroid, "Export your strings as xml for Android. All values from 'value' will be escaped, 'android' will be used as-is.\n\nPlurals can be added with a `__pl_<one|other|...>` suffix")
-	outputFolder = command.Flag("outputFolder", "Set the output directory where the values-* folders will be generated.").Default("exports").String()
+	outputFolder = command.Flag("outputFolder", "Set the output directory where the values-* folders will be generated. Modules will be generated in subdirectories.").Default("exports").String()
+	flavorFolder = command.Flag("flavorOutputFolder", "Set the output directory for flavors, where {flavor} will be replaced with the flavor name. Only overridden strings are exported.").Default("exports/{flavor}").String()
+	localesConfig = command.Flag("localesConfig", "Generate xml/locales_config.xml listing all exported locales for the per-app language preferences of Android 13.").Default("true").Bool()
+	defaultLocale = command.Flag("defaultLocale", "The BCP-47 locale of your 'default' sheet, used in locales_config.xml and the gradle snippet.").Default("en").String()
+	gradleSnippet = command.Flag("gradleSnippet", "Generate a gradle file at this path that sets resourceConfigurations to all exported locales. Use a .kts extension for the Kotlin DSL.").String()
+	kotlinFolder = command.Flag("kotlinOutputFolder", "Generate a Strings.kt with type-safe accessors in this directory.").String()
+	kotlinPackage = command.Flag("kotlinPackage", "The package of the generated Strings.kt.").String()
+	rPackage = command.Flag("rPackage", "The package of your R class, if it differs from --kotlinPackage.").String()
+	kotlinModulePackages = command.Flag("kotlinModulePackage", "The package of the Strings.kt and the R class of a module, generated in a subdirectory of --kotlinOutputFolder. Defaults to <kotlinPackage>.<module>. Can be repeated.").PlaceHolder("MODULE=PACKAGE").StringMap()
+	command.Validate(func(*kingpin.CmdClause) error {
+		if *kotlinFolder != "" && *kotlinPackage == "" {
+			return errors.New("--kotlinPackage is required to generate Strings.kt")
+		}
+		return nil
+	})
 }
 
 type AndroidWriter struct{}
@@ -57,31 +100,268 @@ func (Writer AndroidWriter) Tag() string {
 	return tagAndroid
 }
 
-func (Writer AndroidWriter) Export(locale string, model *writer.LocalizationModel) {
+func (Writer AndroidWriter) Export(locale writer.Locale, model *writer.LocalizationModel) {
+	exportStrings(*outputFolder, locale, model)
+
+	if locale.IsDefault() && *kotlinFolder != "" {
+		kotlinTemplate, err := template.New("kotlin").Parse(kotlinTemplate)
+		check(err)
+
+		// every module has its own R class, so it gets a separate Strings.kt in its package
+		for _, module := range model.Modules() {
+			pkg, r := kotlinPackages(module)
+			kotlinFile := openFile(path.Join(*kotlinFolder, module), "Strings.kt")
+			err = kotlinTemplate.Execute(kotlinFile, newKotlinModel(model.Module(module), pkg, r))
+			check(err)
+			check(kotlinFile.Close())
+		}
+	}
+}
+
+// kotlinPackages returns the package of the Strings.kt and the R class of the module
+func kotlinPackages(module string) (pkg, r string) {
+	if module == "" {
+		if *rPackage == "" {
+			return *kotlinPackage, *kotlinPackage
+		}
+		return *kotlinPackage, *rPackage
+	}
+	pkg, ok := (*kotlinModulePackages)[module]
+	if !ok {
+		pkg = *kotlinPackage + "." + strings.ToLower(module)
+	}
+	return pkg, pkg
+}
+
+// ExportFlavor writes only the strings overridden for the flavor, e.g. to src/<flavor>/res, since the flavor's resources get merged with main
+func (Writer AndroidWriter) ExportFlavor(flavor string, locale writer.Locale, model *writer.LocalizationModel) {
+	flavored := &writer.LocalizationModel{
+		Headers: model.Headers,
+		Plurals: &map[string]writer.QuantityString{},
+	}
+	for _, group := range model.Groups {
+		g := writer.Group{Name: group.Name}
+		for _, s := range group.Strings {
+			if s.Flavored {
+				g.Strings = append(g.Strings, s)
+			}
+		}
+		if len(g.Strings) > 0 {
+			flavored.Groups = append(flavored.Groups, g)
+		}
+	}
+	if len(flavored.Groups) == 0 {
+		return
+	}
+
+	exportStrings(strings.Replace(*flavorFolder, "{flavor}", flavor, -1), locale, flavored)
+}
+
+// exportStrings writes the generated_strings.xml of every module
+func exportStrings(outputFolder string, locale writer.Locale, model *writer.LocalizationModel) {
 	var folder string
-	if locale == "default" {
+	if locale.IsDefault() {
 		folder = "values"
 	} else {
-		folder = "values-" + locale
+		folder = "values-" + locale.Qualifier()
 	}
 
-	localeFolder := path.Join(*outputFolder, folder)
-	f := openFile(localeFolder, "generated_strings.xml")
-	defer f.Close()
-
-	template, err := template.New("file").Parse(androidTemplate)
+	template, err := template.New("file").Funcs(funcs).Parse(androidTemplate)
 	check(err)
 
-	err = template.Execute(f, model)
+	for _, module := range model.Modules() {
+		localeFolder := path.Join(outputFolder, module, folder)
+		f := openFile(localeFolder, "generated_strings.xml")
+		err = template.Execute(f, model.Module(module))
+		check(err)
+		check(f.Close())
+	}
+}
+
+// Finish writes the locales_config.xml and the optional gradle snippet listing all exported locales
+func (Writer AndroidWriter) Finish(locales []writer.Locale) {
+	base, err := writer.ParseLocale(writer.DefaultLocale, *defaultLocale)
 	check(err)
+
+	var tags, qualifiers []string
+	seen := make(map[string]bool)
+	for _, locale := range locales {
+		if locale.IsDefault() {
+			locale = base
+		}
+		if seen[locale.String()] {
+			continue
+		}
+		seen[locale.String()] = true
+		tags = append(tags, locale.String())
+		qualifiers = append(qualifiers, locale.Qualifier())
+	}
+	sort.Strings(tags)
+	sort.Strings(qualifiers)
+
+	if *localesConfig {
+		template, err := template.New("locales").Parse(localesConfigTemplate)
+		check(err)
+		f := openFile(path.Join(*outputFolder, "xml"), "locales_config.xml")
+		err = template.Execute(f, tags)
+		check(err)
+		check(f.Close())
+	}
+
+	if *gradleSnippet != "" {
+		template, err := template.New("gradle").Parse(gradleTemplate)
+		check(err)
+		f := openFile(path.Dir(*gradleSnippet), path.Base(*gradleSnippet))
+		err = template.Execute(f, struct {
+			Kotlin     bool
+			Qualifiers []string
+		}{strings.HasSuffix(*gradleSnippet, ".kts"), qualifiers})
+		check(err)
+		check(f.Close())
+	}
 }
 
 var iosStringFormat = regexp.MustCompile("%(\\d\\$)?@")
 
-func (Writer AndroidWriter) Normalize(s string) string {
-	return iosStringFormat.ReplaceAllStringFunc(s, func(s string) string {
+// a format specifier, or a single % that is not part of one
+var percent = regexp.MustCompile(writer.FormatSpecifier.String() + "|%")
+
+var xmlEntities = map[rune]string{'&': "&amp;", '<': "&lt;", '>': "&gt;"}
+
+var funcs = template.FuncMap{
+	"unformatted": unformatted,
+	"stringName": func(key string) string {
+		return stringName(writer.CompositeKeyOf(key))
+	},
+}
+
+// hasFormatArguments returns true if s contains format specifiers that consume an argument
+func hasFormatArguments(s string) bool {
+	return len(writer.FormatArguments(s)) > 0
+}
+
+// unformatted strings contain a % but no format arguments and need formatted="false", since getString(id) would not unescape a %%
+func unformatted(s string) bool {
+	return strings.Contains(s, "%") && !hasFormatArguments(s)
+}
+
+// Normalize converts iOS format specifiers and escapes the string as required by aapt2.
+// A single % is only escaped in strings with format arguments, others are marked with formatted="false".
+// Html values keep their markup and will be wrapped in CDATA.
+// https://developer.android.com/guide/topics/resources/string-resource#escaping_quotes
+func (Writer AndroidWriter) Normalize(s string, html bool) string {
+	s = iosStringFormat.ReplaceAllStringFunc(s, func(s string) string {
 		return strings.Replace(s, "@", "s", 1)
 	})
+	if hasFormatArguments(s) {
+		s = percent.ReplaceAllStringFunc(s, func(s string) string {
+			if s == "%" {
+				return "%%"
+			}
+			return s
+		})
+	}
+	if html {
+		s = strings.Replace(s, "]]>", "]]]]><![CDATA[>", -1)
+	}
+
+	var escaped strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			escaped.WriteString("\\\\")
+		case '\'':
+			escaped.WriteString("\\'")
+		case '"':
+			escaped.WriteString("\\\"")
+		case '&', '<', '>':
+			if html {
+				escaped.WriteRune(r)
+			} else {
+				escaped.WriteString(xmlEntities[r])
+			}
+		case '\n':
+			escaped.WriteString("\\n")
+		case '\t':
+			escaped.WriteString("\\t")
+		case '@', '?':
+			// a leading @ or ? would be parsed as a resource or attribute reference
+			if i == 0 {
+				escaped.WriteRune('\\')
+			}
+			escaped.WriteRune(r)
+		case ' ':
+			// aapt2 trims the value and collapses whitespace, so only single spaces within the text are kept as-is
+			if i == 0 || i == len(s)-1 || s[i-1] == ' ' {
+				escaped.WriteString("\\u0020")
+			} else {
+				escaped.WriteRune(r)
+			}
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(&escaped, "\\u%04x", r)
+			} else {
+				escaped.WriteRune(r)
+			}
+		}
+	}
+	return escaped.String()
+}
+
+// Unescape reverts the escaping of aapt2, i.e. the value as it will be shown in the app, and the %% of formatted strings.
+// Unescaped quotes are removed and preserve whitespace, which is otherwise collapsed.
+func Unescape(s string) string {
+	var unescaped strings.Builder
+	runes := []rune(strings.TrimSpace(s))
+	quoted, space := false, false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r != '"' && !quoted && unicode.IsSpace(r) {
+			if !space {
+				unescaped.WriteRune(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '\\' && i+1 < len(runes):
+			i++
+			switch runes[i] {
+			case 'n':
+				unescaped.WriteRune('\n')
+			case 't':
+				unescaped.WriteRune('\t')
+			case 'u':
+				if i+4 < len(runes) {
+					if code, err := strconv.ParseUint(string(runes[i+1:i+5]), 16, 32); err == nil {
+						unescaped.WriteRune(rune(code))
+						i += 4
+						continue
+					}
+				}
+				unescaped.WriteRune(runes[i])
+			default:
+				unescaped.WriteRune(runes[i])
+			}
+		default:
+			unescaped.WriteRune(r)
+		}
+	}
+
+	s = unescaped.String()
+	if hasFormatArguments(s) {
+		s = percent.ReplaceAllStringFunc(s, func(s string) string {
+			if s == "%%" {
+				return "%"
+			}
+			return s
+		})
+	}
+	return s
 }
 
 func openFile(folder string, name string) *os.File {
@@ -0,0 +1,39 @@
+package android
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		value, want string
+		html        bool
+	}{
+		{value: "Rock 'n' Roll", want: `Rock \'n\' Roll`},
+		{value: `What's "This"`, want: `What\'s \"This\"`},
+		{value: "@home", want: `\@home`},
+		{value: "?attr", want: `\?attr`},
+		{value: "mail@example.com?", want: "mail@example.com?"},
+		{value: "Questions & Answers", want: "Questions &amp; Answers"},
+		{value: "a < b > c", want: "a &lt; b &gt; c"},
+		{value: "<b>bold</b> & more", want: "<b>bold</b> & more", html: true},
+		{value: "100% sure", want: "100% sure"},
+		{value: "%d of 100%", want: "%d of 100%%"},
+		{value: "%1$@ and %@", want: "%1$s and %s"},
+		{value: "line\nbreak\ttab", want: `line\nbreak\ttab`},
+		{value: `back\slash`, want: `back\\slash`},
+		{value: "  two  spaces ", want: `\u0020\u0020two \u0020spaces\u0020`},
+		{value: "a]]>b", want: "a]]]]><![CDATA[>b", html: true},
+	}
+	for _, test := range tests {
+		if got := (AndroidWriter{}).Normalize(test.value, test.html); got != test.want {
+			t.Errorf("Normalize(%q, %v) = %q, want %q", test.value, test.html, got, test.want)
+		}
+	}
+}
+
+func TestUnformatted(t *testing.T) {
+	for value, want := range map[string]bool{"100% sure": true, "%d of 100%": false, "%1$s": false, "no percent": false} {
+		if got := unformatted(value); got != want {
+			t.Errorf("unformatted(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package android
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bleeding182/localization/writer"
+	"github.com/iancoleman/strcase"
+)
+
+const kotlinTemplate = `{{range $header := $.Headers -}}
+// {{$header}}
+{{end -}}
+@file:Suppress("unused")
+
+package {{.Package}}
+
+import android.content.Context
+{{- if ne .RPackage .Package}}
+import {{.RPackage}}.R
+{{- end}}
+
+object Strings {
+{{- range $g := $.Groups}}
+    object {{.Name}} {
+        {{- range $s := $g.Strings}}
+        fun {{.Name}}(context: Context{{template "parameters" .Parameters}}): String =
+            context.getString(R.string.{{.RName}}{{template "arguments" .Arguments}})
+        {{- end}}
+        {{- range $p := $g.Plurals}}
+        fun {{.Name}}(context: Context{{template "parameters" .Parameters}}): String =
+            context.resources.getQuantityString(R.plurals.{{.RName}}, count{{template "arguments" .Arguments}})
+        {{- end}}
+    }
+{{end -}}
+}
+{{- define "parameters"}}{{range .}}, {{.Name}}: {{.Type}}{{end}}{{end}}
+{{- define "arguments"}}{{range .}}, {{.Name}}{{end}}{{end}}
+`
+
+var kotlinTypes = map[writer.ArgumentType]string{
+	writer.IntArgument:    "Int",
+	writer.DoubleArgument: "Double",
+	writer.StringArgument: "String",
+}
+
+type kotlinModel struct {
+	Headers           *[]string
+	Package, RPackage string
+	Groups            []kotlinGroup
+}
+
+type kotlinGroup struct {
+	Name    string
+	Strings []kotlinString
+	Plurals []kotlinString
+}
+
+// kotlinString is a function returning the string resource with its typed format arguments
+type kotlinString struct {
+	Key        string
+	RName      string // the name of the field in R
+	Name       string
+	Parameters []writer.Parameter
+	Arguments  []writer.Parameter // the format arguments, which don't include the count of a plural unless it replaces the first one
+}
+
+// newKotlinModel groups the strings for Strings.kt. Plurals replace their quantity strings with a single function taking the count,
+// the same way as Strings.swift does.
+func newKotlinModel(model *writer.LocalizationModel, pkg, rPackage string) kotlinModel {
+	plurals := make(map[string][]kotlinString)
+	for _, plural := range *model.Plurals {
+		other, ok := plural.Values[writer.QuantityOf("other")]
+		if !ok {
+			continue
+		}
+		value, ok := writer.FindString(model, other.Key.Original())
+		if !ok {
+			continue
+		}
+		s := newKotlinString(writer.CompositeKeyOf(plural.Key), value.Value)
+		s.RName = rName(plural.Key)
+		s.Parameters = writer.PluralParameters(value.Value, kotlinTypes)
+		s.Arguments = s.Parameters
+		if !writer.CountReplacesArgument(value.Value) {
+			s.Arguments = s.Parameters[1:]
+		}
+
+		group := other.Key.Group()
+		plurals[group] = append(plurals[group], s)
+	}
+
+	kotlin := kotlinModel{Headers: model.Headers, Package: pkg, RPackage: rPackage}
+	for _, g := range model.Groups {
+		group := kotlinGroup{
+			Name:    strcase.ToCamel(g.Name),
+			Plurals: plurals[g.Name],
+		}
+		sort.Slice(group.Plurals, func(i, j int) bool {
+			return group.Plurals[i].Key < group.Plurals[j].Key
+		})
+		for _, s := range g.Strings {
+			key := writer.CompositeKeyOf(s.Key)
+			if key.Quantity() != "" {
+				continue
+			}
+			group.Strings = append(group.Strings, newKotlinString(key, s.Value))
+		}
+		kotlin.Groups = append(kotlin.Groups, group)
+	}
+	return kotlin
+}
+
+func newKotlinString(key writer.CompositeKey, value string) kotlinString {
+	parameters := writer.Parameters(value, kotlinTypes)
+	return kotlinString{
+		Key:        key.Original(),
+		RName:      rName(stringName(key)),
+		Name:       strcase.ToLowerCamel(key.Identifier()),
+		Parameters: parameters,
+		Arguments:  parameters,
+	}
+}
+
+// stringName is the name of the <string> resource. Quantities always use the __pl_ suffix, since other patterns like key#one
+// are no valid resource names.
+func stringName(key writer.CompositeKey) string {
+	if key.Quantity() == "" {
+		return key.Original()
+	}
+	return rName(key.PlainKey()) + "__pl_" + key.Quantity()
+}
+
+// rName is the name of the field in R, where dots are replaced with underscores
+func rName(key string) string {
+	return strings.Replace(key, ".", "_", -1)
+}
+
+// Identifiers of the resource in R and, if Strings.kt is generated, of its object and function
+func (Writer AndroidWriter) Identifiers(key writer.CompositeKey) []writer.Identifier {
+	identifiers := []writer.Identifier{{Name: "R.string." + rName(stringName(key)), Source: "key " + key.Original()}}
+	if key.Quantity() != "" {
+		identifiers = append(identifiers, writer.Identifier{Name: "R.plurals." + rName(key.PlainKey()), Source: writer.KeySource(key)})
+	}
+	if *kotlinFolder != "" {
+		object := "Strings." + strcase.ToCamel(key.Group())
+		identifiers = append(identifiers,
+			writer.Identifier{Name: object, Source: writer.GroupSource(key)},
+			writer.Identifier{Name: object + "." + strcase.ToLowerCamel(key.Identifier()), Source: writer.KeySource(key)},
+		)
+	}
+	return identifiers
+}
+
+var resourceName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// ValidateKey checks that the key is a valid resource name and, if Strings.kt is generated, a valid Kotlin name
+func (Writer AndroidWriter) ValidateKey(key writer.CompositeKey) error {
+	if !resourceName.MatchString(stringName(key)) {
+		return fmt.Errorf("%q is not a valid Android resource name", stringName(key))
+	}
+	if writer.JavaKeywords[rName(key.PlainKey())] {
+		return fmt.Errorf("%q is a reserved word in Java and can't be used as resource name", key.PlainKey())
+	}
+	if *kotlinFolder == "" {
+		return nil
+	}
+	if err := writer.CheckIdentifier("Kotlin", strcase.ToCamel(key.Group()), writer.KotlinKeywords); err != nil {
+		return err
+	}
+	return writer.CheckIdentifier("Kotlin", strcase.ToLowerCamel(key.Identifier()), writer.KotlinKeywords)
+}
@@ -0,0 +1,68 @@
+package android
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bleeding182/localization/writer"
+)
+
+func TestKotlinPluralCount(t *testing.T) {
+	tests := []struct {
+		value                 string
+		parameters, arguments []string
+	}{
+		{value: "%d bottles", parameters: []string{"count"}, arguments: []string{"count"}},
+		{value: "%1$d bottles of %2$s", parameters: []string{"count", "p2"}, arguments: []string{"count", "p2"}},
+		{value: "%s has items", parameters: []string{"count", "p1"}, arguments: []string{"p1"}},
+		{value: "items", parameters: []string{"count"}, arguments: []string{}},
+	}
+	for _, test := range tests {
+		key := writer.CompositeKeyOf("cart__items__pl_other")
+		model := &writer.LocalizationModel{
+			Groups: []writer.Group{{Name: "cart", Strings: []writer.AndroidString{{Key: key.Original(), Value: test.value}}}},
+			Plurals: &map[string]writer.QuantityString{"cart__items": {
+				Key:    "cart__items",
+				Values: map[writer.Quantity]writer.LocalizedString{writer.QuantityOf("other"): {Key: key, Value: test.value}},
+			}},
+		}
+		plurals := newKotlinModel(model, "com.example", "com.example").Groups[0].Plurals
+		if len(plurals) != 1 {
+			t.Fatalf("%q: expected one plural, got %+v", test.value, plurals)
+		}
+		if got := names(plurals[0].Parameters); !reflect.DeepEqual(got, test.parameters) {
+			t.Errorf("%q: parameters %v, want %v", test.value, got, test.parameters)
+		}
+		if got := names(plurals[0].Arguments); !reflect.DeepEqual(got, test.arguments) {
+			t.Errorf("%q: arguments %v, want %v", test.value, got, test.arguments)
+		}
+	}
+}
+
+func names(parameters []writer.Parameter) []string {
+	names := []string{}
+	for _, p := range parameters {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestKotlinPackages(t *testing.T) {
+	pkg, r, modules := "com.example.app", "", map[string]string{"Checkout": "com.example.checkout.ui"}
+	kotlinPackage, rPackage, kotlinModulePackages = &pkg, &r, &modules
+
+	tests := map[string][2]string{
+		"":         {"com.example.app", "com.example.app"},
+		"Checkout": {"com.example.checkout.ui", "com.example.checkout.ui"},
+		"Profile":  {"com.example.app.profile", "com.example.app.profile"},
+	}
+	for module, want := range tests {
+		if pkg, r := kotlinPackages(module); pkg != want[0] || r != want[1] {
+			t.Errorf("kotlinPackages(%q) = %q, %q, want %q", module, pkg, r, want)
+		}
+	}
+	r = "com.example.r"
+	if _, got := kotlinPackages(""); got != "com.example.r" {
+		t.Errorf("--rPackage should be used for the default module, got %q", got)
+	}
+}
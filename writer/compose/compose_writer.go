@@ -0,0 +1,195 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/bleeding182/localization/writer"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const composeTemplate = `<?xml version="1.0" encoding="utf-8"?>
+{{range $header := $.Headers -}}
+<!-- {{$header}} -->
+{{end -}}
+<resources>
+{{- range $g := $.Groups}}
+    <!-- region {{.Name}} -->
+    {{- range $s := $g.Strings}}
+    {{- if .Comment}}
+    <!-- {{.Comment}} -->
+    {{- end}}
+    <string name="{{.Name}}">{{.Value}}</string>
+    {{- end}}
+    {{- range $p := $g.Plurals}}
+    <plurals name="{{.Name}}">
+    {{- range $q := .Quantities}}
+        <item quantity="{{.Quantity}}">{{.Value}}</item>
+    {{- end}}
+    </plurals>
+    {{- end}}
+    <!-- endregion -->
+{{end}}
+</resources>
+`
+
+const tagCompose = "compose"
+
+var outputFolder *string
+
+func (writer ComposeWriter) RegisterCommand(app *kingpin.Application) {
+	command := app.Command(tagCompose, "Export your strings as Compose Multiplatform resources. This will generate strings.xml in values-* folders, accessible as Res.string.* and Res.plurals.*")
+	outputFolder = command.Flag("outputFolder", "Set the composeResources directory where the values-* folders will be generated. Modules will be generated in subdirectories.").Default("exports/composeResources").String()
+}
+
+// ComposeWriter exports strings and plurals for the resources library of Compose Multiplatform
+type ComposeWriter struct{}
+
+func (writer ComposeWriter) Tag() string {
+	return tagCompose
+}
+
+type composeModel struct {
+	Headers *[]string
+	Groups  []composeGroup
+}
+
+type composeGroup struct {
+	Name    string
+	Strings []composeString
+	Plurals []composePlural
+}
+
+type composeString struct {
+	Name, Value, Comment string
+}
+
+type composePlural struct {
+	Name       string
+	Quantities []composeQuantity
+}
+
+type composeQuantity struct {
+	Quantity writer.Quantity
+	Value    string
+}
+
+func (writer ComposeWriter) Export(locale writer.Locale, model *writer.LocalizationModel) {
+	var folder string
+	if locale.IsDefault() {
+		folder = "values"
+	} else {
+		qualifier, err := locale.LegacyQualifier()
+		check(err)
+		folder = "values-" + qualifier
+	}
+
+	template, err := template.New("compose").Parse(composeTemplate)
+	check(err)
+
+	for _, module := range model.Modules() {
+		f := openFile(path.Join(*outputFolder, module, folder), "strings.xml")
+		err = template.Execute(f, newComposeModel(model.Module(module)))
+		check(err)
+		check(f.Close())
+	}
+}
+
+// newComposeModel inlines the quantity strings into their plurals, since compose resources can't reference other strings
+func newComposeModel(model *writer.LocalizationModel) composeModel {
+	values := make(map[string]string)
+	for _, group := range model.Groups {
+		for _, s := range group.Strings {
+			values[s.Key] = s.Value
+		}
+	}
+
+	plurals := make(map[string][]composePlural)
+	for _, plural := range *model.Plurals {
+		p := composePlural{Name: writer.ResourceName(plural.Key)}
+		var group string
+		for quantity, s := range plural.Values {
+			p.Quantities = append(p.Quantities, composeQuantity{quantity, values[s.Key.Original()]})
+			group = s.Key.Group()
+		}
+		sort.Slice(p.Quantities, func(i, j int) bool {
+			return p.Quantities[i].Quantity < p.Quantities[j].Quantity
+		})
+		plurals[group] = append(plurals[group], p)
+	}
+
+	compose := composeModel{Headers: model.Headers}
+	for _, g := range model.Groups {
+		group := composeGroup{Name: g.Name, Plurals: plurals[g.Name]}
+		sort.Slice(group.Plurals, func(i, j int) bool {
+			return group.Plurals[i].Name < group.Plurals[j].Name
+		})
+		for _, s := range g.Strings {
+			if writer.CompositeKeyOf(s.Key).Quantity() != "" {
+				continue
+			}
+			group.Strings = append(group.Strings, composeString{writer.ResourceName(s.Key), s.Value, s.Comment})
+		}
+		compose.Groups = append(compose.Groups, group)
+	}
+	return compose
+}
+
+// Identifiers of the generated Res.string.* and Res.plurals.* accessors
+func (w ComposeWriter) Identifiers(key writer.CompositeKey) []writer.Identifier {
+	if key.Quantity() != "" {
+		return []writer.Identifier{{Name: "Res.plurals." + writer.ResourceName(key.PlainKey()), Source: writer.KeySource(key)}}
+	}
+	return []writer.Identifier{{Name: "Res.string." + writer.ResourceName(key.Original()), Source: writer.KeySource(key)}}
+}
+
+// ValidateKey checks that the accessor isn't a reserved word in Kotlin
+func (w ComposeWriter) ValidateKey(key writer.CompositeKey) error {
+	return writer.CheckIdentifier("Kotlin", writer.ResourceName(key.PlainKey()), writer.KotlinKeywords)
+}
+
+var xmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\\", "\\\\",
+	"\n", "\\n",
+	"\t", "\\t",
+)
+
+// Normalize converts all format specifiers to the positional %1$s and %1$d, the only ones supported by compose resources,
+// and escapes the string for the xml file. Html is not supported and will be escaped as text.
+func (writer ComposeWriter) Normalize(s string, html bool) string {
+	return xmlEscaper.Replace(positionalArguments(s))
+}
+
+func positionalArguments(s string) string {
+	return writer.ReplaceArguments(s, func(specifier string, position int, argumentType writer.ArgumentType) string {
+		conversion := "s"
+		if argumentType == writer.IntArgument {
+			conversion = "d"
+		}
+		return fmt.Sprintf("%%%d$%v", position, conversion)
+	})
+}
+
+func openFile(folder string, name string) *os.File {
+	foldername := fmt.Sprintf("%v", folder)
+	os.MkdirAll(foldername, os.ModePerm)
+
+	filename := fmt.Sprintf("%v/%v", foldername, name)
+	f, err := os.Create(filename)
+	check(err)
+	return f
+}
+
+func check(e error) {
+	if e != nil {
+		panic(e)
+	}
+}
@@ -0,0 +1,18 @@
+package compose
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"%@ has %d items":     "%1$s has %2$d items",
+		"%2$s before %1$.2f":  "%2$s before %1$s",
+		"%'d points":          "%1$d points",
+		"100%% & <b>more</b>": "100%% &amp; &lt;b&gt;more&lt;/b&gt;",
+		"line\nbreak":         `line\nbreak`,
+	}
+	for value, want := range tests {
+		if got := (ComposeWriter{}).Normalize(value, false); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
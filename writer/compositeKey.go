@@ -1,19 +1,90 @@
 package writer
 
-import "regexp"
+import (
+	"fmt"
+	"regexp"
+)
 
-// We support keys in the form of [group]__?[identifier]__pl_[<one|other|etc>]
-// For multi-word groups we can use __ instead to create a long_group__identifier
-// 0: original key
-// 1: key without (optional) quanity
-// 2: group part of key (part until __ or first part until _)
-// 3: identifier - part without group or quantity
-// 4: optional quantity
-var keyRegex = regexp.MustCompile("^((.*?)(?:_{1,2})((?:[a-zA-Z0-9]+_)*[a-zA-Z0-9]*))(?:__pl_(.*))?$")
+// KeyPatterns are the predefined key grammars that can be selected by name.
+// Each pattern must contain the named groups `key` (the key without quantity), `group` and `identifier`, and optionally `quantity`.
+var KeyPatterns = map[string]string{
+	// We support keys in the form of [group]__?[identifier]__pl_[<one|other|etc>]
+	// For multi-word groups we can use __ instead to create a long_group__identifier
+	"default": `^(?P<key>(?P<group>.*?)(?:_{1,2})(?P<identifier>(?:[a-zA-Z0-9]+_)*[a-zA-Z0-9]*))(?:__pl_(?P<quantity>.*))?$`,
+	// Keys in the form of [group].[sub].[identifier]#[<one|other|etc>], where everything up to the last dot is the group
+	"dotted": `^(?P<key>(?P<group>.+)\.(?P<identifier>[^.#]+))(?:#(?P<quantity>.*))?$`,
+}
+
+var keyRegex = regexp.MustCompile(KeyPatterns["default"])
+
+// parts of a CompositeKey
+const (
+	original = iota
+	plainKey
+	group
+	identifier
+	quantity
+)
+
+var keyGroups = []string{"key", "group", "identifier", "quantity"}
+
+// SetKeyPattern changes the grammar used to parse keys. The pattern is either the name of one of the KeyPatterns or a regular expression.
+func SetKeyPattern(pattern string) error {
+	if predefined, ok := KeyPatterns[pattern]; ok {
+		pattern = predefined
+	}
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid key pattern: %v", err)
+	}
+	for _, name := range keyGroups[:3] {
+		if subexpIndex(regex, name) < 0 {
+			return fmt.Errorf("key pattern %q is missing the named group (?P<%v>...)", pattern, name)
+		}
+	}
+	keyRegex = regex
+	return nil
+}
+
+func subexpIndex(regex *regexp.Regexp, name string) int {
+	for i, subexp := range regex.SubexpNames() {
+		if subexp == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseKey parses the key using the current key pattern, returning an error if it doesn't match
+func ParseKey(key string) (CompositeKey, error) {
+	match := keyRegex.FindStringSubmatch(key)
+	if match == nil {
+		return CompositeKey{}, fmt.Errorf("key %q does not match the pattern %v", key, keyRegex)
+	}
+
+	parts := []string{match[0]}
+	for _, name := range keyGroups {
+		part := ""
+		if index := subexpIndex(keyRegex, name); index >= 0 {
+			part = match[index]
+		}
+		parts = append(parts, part)
+	}
+
+	if parts[quantity] != "" && !IsQuantity(parts[quantity]) {
+		return CompositeKey{}, fmt.Errorf("key %q uses an unknown quantity %q", key, parts[quantity])
+	}
+	return CompositeKey{parts}, nil
+}
 
 // CompositeKeyOf returns a new CompositeKey after parsing the key argument.
+// It should only be used for keys that were already validated with ParseKey, e.g. CompositeKey.Original(), and panics otherwise.
 func CompositeKeyOf(key string) CompositeKey {
-	return CompositeKey{keyRegex.FindStringSubmatch(key)}
+	compositeKey, err := ParseKey(key)
+	if err != nil {
+		panic(err)
+	}
+	return compositeKey
 }
 
 // CompositeKey represents a key that consists of [group]_[identifier]_[plural]
@@ -23,25 +94,25 @@ type CompositeKey struct {
 
 // Original the complete, original key
 func (key CompositeKey) Original() string {
-	return key.parts[0]
+	return key.parts[original]
 }
 
 // PlainKey the full key without any (optional) quantity
 func (key CompositeKey) PlainKey() string {
-	return key.parts[1]
+	return key.parts[plainKey]
 }
 
 // Group of the key, the first part before any `_` or `__` for longer names
 func (key CompositeKey) Group() string {
-	return key.parts[2]
+	return key.parts[group]
 }
 
 // Identifier of the Key without a group or (optional) quantity
 func (key CompositeKey) Identifier() string {
-	return key.parts[3]
+	return key.parts[identifier]
 }
 
 // Quantity of the key (optional)
 func (key CompositeKey) Quantity() string {
-	return key.parts[4]
+	return key.parts[quantity]
 }
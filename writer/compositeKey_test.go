@@ -0,0 +1,56 @@
+package writer
+
+import "testing"
+
+func TestParseKey(t *testing.T) {
+	defer SetKeyPattern("default")
+
+	tests := []struct {
+		pattern, key                          string
+		plainKey, group, identifier, quantity string
+	}{
+		{"default", "greeting_hello", "greeting_hello", "greeting", "hello", ""},
+		{"default", "base_app__app_name", "base_app__app_name", "base_app", "app_name", ""},
+		{"default", "cart_items__pl_one", "cart_items", "cart", "items", "one"},
+		{"dotted", "home.greeting", "home.greeting", "home", "greeting", ""},
+		{"dotted", "cart.checkout.pay", "cart.checkout.pay", "cart.checkout", "pay", ""},
+		{"dotted", "cart.items#other", "cart.items", "cart", "items", "other"},
+		{`^(?P<key>(?P<group>[a-z]+)-(?P<identifier>[a-z]+))$`, "home-title", "home-title", "home", "title", ""},
+	}
+	for _, test := range tests {
+		if err := SetKeyPattern(test.pattern); err != nil {
+			t.Fatal(err)
+		}
+		key, err := ParseKey(test.key)
+		if err != nil {
+			t.Errorf("%v: %v", test.key, err)
+			continue
+		}
+		if key.Original() != test.key || key.PlainKey() != test.plainKey || key.Group() != test.group || key.Identifier() != test.identifier || key.Quantity() != test.quantity {
+			t.Errorf("%v: got %q, %q, %q, %q", test.key, key.PlainKey(), key.Group(), key.Identifier(), key.Quantity())
+		}
+	}
+}
+
+func TestParseInvalidKey(t *testing.T) {
+	defer SetKeyPattern("default")
+
+	if err := SetKeyPattern("dotted"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"greeting_hello", "cart.items#lots"} {
+		if _, err := ParseKey(key); err == nil {
+			t.Errorf("%q should be rejected", key)
+		}
+	}
+}
+
+func TestSetKeyPattern(t *testing.T) {
+	defer SetKeyPattern("default")
+
+	for _, pattern := range []string{"(", `^(?P<key>(?P<group>.+)\.(?P<id>.+))$`} {
+		if err := SetKeyPattern(pattern); err == nil {
+			t.Errorf("%q should be rejected", pattern)
+		}
+	}
+}
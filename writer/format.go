@@ -0,0 +1,153 @@
+package writer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ArgumentType of a format argument, e.g. %d or %1$@
+type ArgumentType int
+
+const (
+	IntArgument    ArgumentType = iota // %d, %i, %u, %x, %o, %c
+	DoubleArgument                     // %f, %e, %g, %a
+	StringArgument                     // %@, %s
+)
+
+// FormatSpecifier matches the format specifiers of iOS and Android (Java), e.g. %d, %1$@, %.2f, %n or %%.
+// 1: optional position, 2: conversion
+var FormatSpecifier = regexp.MustCompile(`%(?:(\d+)\$)?[-#+0,'(]*\d*(?:\.\d+)?(?:hh|h|ll|l|q|z|t|j|L)?([@dDiuUxXoOcCpfFeEgGaAsSbBhHn%]|[tT][a-zA-Z])`)
+
+var argumentTypes = map[byte]ArgumentType{
+	'@': StringArgument, 's': StringArgument, 'S': StringArgument,
+	'b': StringArgument, 'B': StringArgument, 'h': StringArgument, 'H': StringArgument,
+	'f': DoubleArgument, 'F': DoubleArgument, 'e': DoubleArgument, 'E': DoubleArgument,
+	'g': DoubleArgument, 'G': DoubleArgument, 'a': DoubleArgument, 'A': DoubleArgument,
+}
+
+// Parameter of a generated function for a format argument, e.g. p1 of type Int
+type Parameter struct {
+	Name, Type string
+}
+
+// Parameters names the format arguments of the value p1, p2, etc. and maps their types to the type names of the generated language
+func Parameters(value string, typeNames map[ArgumentType]string) []Parameter {
+	arguments := FormatArguments(value)
+	parameters := make([]Parameter, len(arguments))
+	for i, argument := range arguments {
+		parameters[i] = Parameter{fmt.Sprintf("p%d", i+1), typeNames[argument]}
+	}
+	return parameters
+}
+
+// PluralParameters of a plural function always start with the count, which either replaces the first argument or is added before it.
+// See CountReplacesArgument.
+func PluralParameters(value string, typeNames map[ArgumentType]string) []Parameter {
+	parameters := Parameters(value, typeNames)
+	count := Parameter{Name: "count", Type: typeNames[IntArgument]}
+	if CountReplacesArgument(value) {
+		parameters[0] = count
+		return parameters
+	}
+	return append([]Parameter{count}, parameters...)
+}
+
+// FindString returns the string of the model with the key, e.g. the "other" quantity of a plural to generate its function from
+func FindString(model *LocalizationModel, key string) (AndroidString, bool) {
+	for _, group := range model.Groups {
+		for _, s := range group.Strings {
+			if s.Key == key {
+				return s, true
+			}
+		}
+	}
+	return AndroidString{}, false
+}
+
+// SampleArguments are the values RenderSample uses for the format arguments
+var SampleArguments = map[ArgumentType]string{
+	IntArgument:    "1000",
+	DoubleArgument: "10.00",
+	StringArgument: "XXXXXXXXXX",
+}
+
+// RenderSample replaces all format arguments with the SampleArguments of their type, e.g. to check the length of a string
+func RenderSample(format string) string {
+	rendered := ReplaceArguments(format, func(specifier string, position int, argumentType ArgumentType) string {
+		return SampleArguments[argumentType]
+	})
+	return sampleEscapes.Replace(rendered)
+}
+
+// sampleEscapes renders the specifiers that don't consume an argument
+var sampleEscapes = strings.NewReplacer("%%", "%", "%n", "\n")
+
+// FormatArguments returns the types of all arguments used by the format string, ordered by their position.
+// Positions that are never referenced are treated as strings.
+func FormatArguments(format string) []ArgumentType {
+	arguments := make(map[int]ArgumentType)
+	count := 0
+	ReplaceArguments(format, func(specifier string, position int, argumentType ArgumentType) string {
+		arguments[position] = argumentType
+		if position > count {
+			count = position
+		}
+		return specifier
+	})
+
+	types := make([]ArgumentType, count)
+	for i := range types {
+		argumentType, ok := arguments[i+1]
+		if !ok {
+			argumentType = StringArgument
+		}
+		types[i] = argumentType
+	}
+	return types
+}
+
+// CountReplacesArgument returns true if the first argument of a plural is an int, which will then be passed the count.
+// Otherwise the count is passed as an additional argument before all others.
+func CountReplacesArgument(format string) bool {
+	arguments := FormatArguments(format)
+	return len(arguments) > 0 && arguments[0] == IntArgument
+}
+
+// ShiftArguments makes all format specifiers positional and moves them by offset, e.g. "%@ and %d" becomes "%2$@ and %3$d" for an offset of 1
+func ShiftArguments(format string, offset int) string {
+	return ReplaceArguments(format, func(specifier string, position int, argumentType ArgumentType) string {
+		return "%" + strconv.Itoa(position+offset) + "$" + specifier[1:]
+	})
+}
+
+// ReplaceArguments replaces every format specifier that consumes an argument, e.g. %d or %2$@, with the result of replace.
+// The specifier is passed without its position, e.g. %.2f for %3$.2f, along with the position of its argument.
+func ReplaceArguments(format string, replace func(specifier string, position int, argumentType ArgumentType) string) string {
+	var replaced strings.Builder
+	last, next := 0, 1
+	for _, match := range FormatSpecifier.FindAllStringSubmatchIndex(format, -1) {
+		conversion := format[match[4]]
+		if conversion == '%' || conversion == 'n' {
+			continue
+		}
+		specifier, position := format[match[0]:match[1]], next
+		if match[2] >= 0 {
+			position, _ = strconv.Atoi(format[match[2]:match[3]])
+			specifier = "%" + format[match[3]+1:match[1]]
+		} else {
+			next++
+		}
+
+		argumentType, ok := argumentTypes[conversion]
+		if !ok {
+			argumentType = IntArgument
+		}
+		replaced.WriteString(format[last:match[0]])
+		replaced.WriteString(replace(specifier, position, argumentType))
+		last = match[1]
+	}
+	replaced.WriteString(format[last:])
+	return replaced.String()
+}
@@ -0,0 +1,67 @@
+package writer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountReplacesArgument(t *testing.T) {
+	tests := map[string]bool{
+		"%d bottles":           true,
+		"%1$d bottles of %2$@": true,
+		"%@ has %d items":      false,
+		"%2$d items of %1$@":   false,
+		"items":                false,
+	}
+	for format, want := range tests {
+		if got := CountReplacesArgument(format); got != want {
+			t.Errorf("CountReplacesArgument(%q) = %v, want %v", format, got, want)
+		}
+	}
+}
+
+func TestShiftArguments(t *testing.T) {
+	tests := map[string]string{
+		"%@ has %d items":     "%2$@ has %3$d items",
+		"%2$d items of %1$@":  "%3$d items of %2$@",
+		"%.2f%% of %s":        "%2$.2f%% of %3$s",
+		"no arguments, 100%%": "no arguments, 100%%",
+	}
+	for format, want := range tests {
+		if got := ShiftArguments(format, 1); got != want {
+			t.Errorf("ShiftArguments(%q, 1) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestFormatArguments(t *testing.T) {
+	tests := map[string][]ArgumentType{
+		"%d of %@":           {IntArgument, StringArgument},
+		"%2$s before %1$.2f": {DoubleArgument, StringArgument},
+		"%'d and %,d":        {IntArgument, IntArgument},
+		"%b and %tY":         {StringArgument, IntArgument},
+		"100%% sure%n":       {},
+		"only %3$s":          {StringArgument, StringArgument, StringArgument},
+	}
+	for format, want := range tests {
+		if got := FormatArguments(format); !reflect.DeepEqual(got, want) {
+			t.Errorf("FormatArguments(%q) = %v, want %v", format, got, want)
+		}
+	}
+}
+
+func TestRenderSample(t *testing.T) {
+	tests := map[string]string{
+		"Hello, %1$s!":            "Hello, XXXXXXXXXX!",
+		"Hello, %@ and %2$@":      "Hello, XXXXXXXXXX and XXXXXXXXXX",
+		"%d items for %.2f €":     "1000 items for 10.00 €",
+		"100%% of %1$d":           "100% of 1000",
+		"First%nSecond":           "First\nSecond",
+		"no arguments, just text": "no arguments, just text",
+	}
+	for format, want := range tests {
+		if got := RenderSample(format); got != want {
+			t.Errorf("RenderSample(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package writer
+
+import "regexp"
+
+// htmlTag matches the tags supported by Html.fromHtml and NSAttributedString, so text like "List<String>" or "a<b" is no markup
+var htmlTag = regexp.MustCompile(`(?i)</?(a|b|big|br|div|em|font|h[1-6]|i|li|ol|p|s|small|span|strike|strong|sub|sup|tt|u|ul)(\s[^<>]*)?/?>`)
+
+// HasMarkup returns true if s contains any known html tags, e.g. <b> or <a href="...">
+func HasMarkup(s string) bool {
+	return htmlTag.MatchString(s)
+}
+
+// StripMarkup removes all known html tags from s
+func StripMarkup(s string) string {
+	return htmlTag.ReplaceAllString(s, "")
+}
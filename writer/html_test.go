@@ -0,0 +1,30 @@
+package writer
+
+import "testing"
+
+func TestHasMarkup(t *testing.T) {
+	tests := map[string]bool{
+		"Some <b>bold</b> text":                         true,
+		`Some <a href="http://www.google.com">Link</a>`: true,
+		"Line<br/>break":                                true,
+		"Line<br />break":                               true,
+		"<FONT color='red'>red</FONT>":                  true,
+		"List<String>":                                  false,
+		"a<b":                                           false,
+		"a < b > c":                                     false,
+		"Map<K, V>":                                     false,
+		"<bold>":                                        false,
+		"plain text":                                    false,
+	}
+	for value, want := range tests {
+		if got := HasMarkup(value); got != want {
+			t.Errorf("HasMarkup(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestStripMarkup(t *testing.T) {
+	if got := StripMarkup(`<b>bold</b> and <a href="x">List<String></a>`); got != "bold and List<String>" {
+		t.Errorf("StripMarkup = %q", got)
+	}
+}
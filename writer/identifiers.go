@@ -0,0 +1,91 @@
+package writer
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Validator can optionally be implemented by a Writer that generates code or resource names from the keys,
+// so keys resulting in colliding or invalid identifiers are reported before anything gets exported.
+type Validator interface {
+	// Identifiers returns all identifiers generated for the key
+	Identifiers(key CompositeKey) []Identifier
+	// ValidateKey returns an error if the key would generate an invalid identifier, e.g. a reserved word
+	ValidateKey(key CompositeKey) error
+}
+
+// Identifier generated for (a part of) a key
+type Identifier struct {
+	Name   string // fully qualified, e.g. Strings.Greeting.GreetingHello or R.string.greeting_hello
+	Source string // what the identifier was generated from, e.g. `key greeting_hello` or `group greeting`. Different sources must not share a name.
+}
+
+// KeySource describes the key as the source of an Identifier, plurals share the same source for all their quantities
+func KeySource(key CompositeKey) string {
+	if key.Quantity() != "" {
+		return "plural " + key.PlainKey()
+	}
+	return "key " + key.Original()
+}
+
+// GroupSource describes the group of the key as the source of an Identifier
+func GroupSource(key CompositeKey) string {
+	return "group " + key.Group()
+}
+
+var invalidNameCharacters = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// ResourceName makes sure the key can be used as a Kotlin identifier for generated accessors like Res.string.* or MR.strings.*,
+// e.g. home_greeting for home.greeting
+func ResourceName(key string) string {
+	name := invalidNameCharacters.ReplaceAllString(key, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// CheckIdentifier returns an error if name is not a valid identifier or one of the reserved words
+func CheckIdentifier(language string, name string, reserved map[string]bool) error {
+	if !identifierRegex.MatchString(name) {
+		return fmt.Errorf("%q is not a valid %v identifier", name, language)
+	}
+	if reserved[name] {
+		return fmt.Errorf("%q is a reserved word in %v", name, language)
+	}
+	return nil
+}
+
+func words(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// SwiftKeywords can't be used as names without backticks. Self, Type and Protocol can't be used as member names.
+var SwiftKeywords = words(
+	"associatedtype", "class", "deinit", "enum", "extension", "fileprivate", "func", "import", "init", "inout", "internal",
+	"let", "open", "operator", "private", "protocol", "public", "rethrows", "static", "struct", "subscript", "typealias", "var",
+	"break", "case", "continue", "default", "defer", "do", "else", "fallthrough", "for", "guard", "if", "in", "repeat", "return",
+	"switch", "where", "while", "as", "Any", "catch", "false", "is", "nil", "super", "self", "Self", "throw", "throws", "true", "try",
+	"Type", "Protocol",
+)
+
+// KotlinKeywords are the hard keywords of Kotlin
+var KotlinKeywords = words(
+	"as", "break", "class", "continue", "do", "else", "false", "for", "fun", "if", "in", "interface", "is", "null", "object",
+	"package", "return", "super", "this", "throw", "true", "try", "typealias", "typeof", "val", "var", "when", "while",
+)
+
+// JavaKeywords can't be used as resource names, since they are generated as fields of R
+var JavaKeywords = words(
+	"abstract", "assert", "boolean", "break", "byte", "case", "catch", "char", "class", "const", "continue", "default", "do",
+	"double", "else", "enum", "extends", "final", "finally", "float", "for", "goto", "if", "implements", "import", "instanceof",
+	"int", "interface", "long", "native", "new", "package", "private", "protected", "public", "return", "short", "static",
+	"strictfp", "super", "switch", "synchronized", "this", "throw", "throws", "transient", "try", "void", "volatile", "while",
+	"true", "false", "null",
+)
@@ -0,0 +1,16 @@
+package writer
+
+import "testing"
+
+func TestResourceName(t *testing.T) {
+	for key, want := range map[string]string{
+		"home_greeting":   "home_greeting",
+		"home.greeting":   "home_greeting",
+		"cart.items-list": "cart_items_list",
+		"1st.place":       "_1st_place",
+	} {
+		if got := ResourceName(key); got != want {
+			t.Errorf("ResourceName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"text/template"
 
 	"github.com/bleeding182/localization/writer"
-	"github.com/iancoleman/strcase"
 
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 )
@@ -51,7 +50,7 @@ const iosStringsDictTemplate = `
                 <string>d</string>
             {{- range $q, $v := .Values}}
                 <key>{{$q}}</key>
-                <string>{{$v.Value}}</string>
+                <string>{{pluralValue $p $v.Value}}</string>
             {{- end}}
             </dict>
         </dict>
@@ -60,41 +59,24 @@ const iosStringsDictTemplate = `
 </plist>
 `
 
-const iosStringsUtilTemplate = `
-import Foundation
-
-{{range $header := $.Headers -}}
-// {{$header}}
-{{end}}
-// swiftlint:disable line_length
-public struct Strings {
-
-    {{- range $g := $.Groups}}
-    public struct {{.Name | camelcase}} {
-        {{- range $as := $g.Strings}}
-        static let {{.Key | camelcase}} = Strings.localized("{{.Key}}", value: "{{.Value}}"
-            {{- if .Comment -}}
-                , comment: "{{.Comment}}"
-            {{- end -}}
-        )
-        {{- end}}
-    }
-    {{end}}
-
-    public static func localized(_ key: String, tableName: String? = nil, bundle: Bundle = Bundle.main, value: String, comment: String = "") -> String {
-        return NSLocalizedString(key, tableName: tableName, bundle: bundle, value: value, comment: comment)
-    }
-}
-`
-
 const tagIos = "ios"
 
 var stringsFolder, utilFolder *string
 
+// InfoPlistGroup is set by the global --infoPlistGroup flag, since the other writers skip the group as well
+var InfoPlistGroup = "infoplist"
+var swiftBundle, swiftTable, swiftAccess, swiftType *string
+var flavorFolder *string
+
 func (writer IOSWriter) RegisterCommand(app *kingpin.Application) {
-	command := app.Command(tagIos, "Export your strings for iOS. This will generate LocalizableGen.strings in *.lproj folders along with a Strings.swift util class.")
+	command := app.Command(tagIos, "Export your strings for iOS. This will generate LocalizableGen.strings in *.lproj folders along with a Strings.swift util class. Modules will be generated as separate tables.")
+	swiftBundle = command.Flag("swiftBundle", "The bundle containing the strings, either `main`, `module` for Swift packages, or any other Swift expression, e.g. Bundle(for: MyClass.self).").Default("main").String()
+	swiftTable = command.Flag("swiftTable", "The table name of the generated .strings and .stringsdict files, used as tableName: in Strings.swift.").Default("LocalizableGen").String()
+	swiftAccess = command.Flag("swiftAccess", "The access level of the generated Swift code.").Default("public").Enum("public", "package", "internal")
+	swiftType = command.Flag("swiftType", "The name of the generated Swift type, also used as file name.").Default("Strings").String()
 	stringsFolder = command.Flag("outputFolder", "Set the output directory where the *.lproj folders will be generated.").Default("exports").String()
 	utilFolder = command.Flag("utilOutputFolder", "Set the output directory where the util-file will be generated. This Strings.swift file contains constants for easier access.").Default("exports").String()
+	flavorFolder = command.Flag("flavorOutputFolder", "Set the output directory for the *.lproj folders of flavors, where {flavor} will be replaced with the flavor name.").Default("exports/{flavor}").String()
 }
 
 type IOSWriter struct{}
@@ -103,52 +85,150 @@ func (writer IOSWriter) Tag() string {
 	return tagIos
 }
 
-var funcs = template.FuncMap{
-	"camelcase": strcase.ToCamel,
+func (writer IOSWriter) Export(locale writer.Locale, model *writer.LocalizationModel) {
+	model = exportStrings(*stringsFolder, locale, model)
+
+	if locale.IsDefault() {
+		utilTemplate, err := template.New("util").Funcs(swiftFuncs).Parse(iosStringsUtilTemplate)
+		check(err)
+		stringsUtilFile := openFile(*utilFolder, *swiftType+".swift")
+		defer stringsUtilFile.Close()
+		err = utilTemplate.Execute(stringsUtilFile, newSwiftModel(model))
+		check(err)
+	}
 }
 
-func (writer IOSWriter) Export(locale string, model *writer.LocalizationModel) {
+// ExportFlavor writes all strings of the flavor into the .lproj folders of its target. Strings.swift is shared by all targets.
+func (writer IOSWriter) ExportFlavor(flavor string, locale writer.Locale, model *writer.LocalizationModel) {
+	exportStrings(strings.Replace(*flavorFolder, "{flavor}", flavor, -1), locale, model)
+}
+
+// exportStrings writes the .strings and .stringsdict files and returns the model without the InfoPlist strings
+func exportStrings(outputFolder string, locale writer.Locale, model *writer.LocalizationModel) *writer.LocalizationModel {
 	var folder string
-	if locale == "default" {
+	if locale.IsDefault() {
 		folder = "Base.lproj"
 	} else {
-		folder = locale + ".lproj"
+		folder = locale.String() + ".lproj"
 	}
 
-	localeFolder := path.Join(*stringsFolder, folder)
+	localeFolder := path.Join(outputFolder, folder)
 
 	stringsTemplate, err := template.New("strings").Parse(iosStringsTemplate)
 	check(err)
-	pluralsTemplate, err := template.New("plurals").Parse(iosStringsDictTemplate)
+	pluralsTemplate, err := template.New("plurals").Funcs(template.FuncMap{"pluralValue": pluralValue}).Parse(iosStringsDictTemplate)
 	check(err)
 
-	stringsFile := openFile(localeFolder, "LocalizableGen.strings")
-	defer stringsFile.Close()
-	err = stringsTemplate.Execute(stringsFile, model)
-	check(err)
+	model, infoPlist := splitInfoPlist(model)
 
-	stringsDictFile := openFile(localeFolder, "LocalizableGen.stringsdict")
-	defer stringsDictFile.Close()
-	err = pluralsTemplate.Execute(stringsDictFile, model)
-	check(err)
+	for _, module := range model.Modules() {
+		table := tableName(module)
+
+		moduleModel := model.Module(module)
 
-	if locale == "default" {
-		utilTemplate, err := template.New("util").Funcs(funcs).Parse(iosStringsUtilTemplate)
+		stringsFile := openFile(localeFolder, table+".strings")
+		err = stringsTemplate.Execute(stringsFile, moduleModel)
 		check(err)
-		stringsUtilFile := openFile(*utilFolder, "Strings.swift")
-		defer stringsUtilFile.Close()
-		err = utilTemplate.Execute(stringsUtilFile, model)
+		check(stringsFile.Close())
+
+		if module != "" && len(*moduleModel.Plurals) == 0 {
+			continue
+		}
+		stringsDictFile := openFile(localeFolder, table+".stringsdict")
+		err = pluralsTemplate.Execute(stringsDictFile, moduleModel)
 		check(err)
+		check(stringsDictFile.Close())
 	}
+
+	if len(infoPlist.Groups) > 0 {
+		infoPlistFile := openFile(localeFolder, "InfoPlist.strings")
+		defer infoPlistFile.Close()
+		err = stringsTemplate.Execute(infoPlistFile, infoPlist)
+		check(err)
+	}
+	return model
+}
+
+// tableName of the module, the default module uses --swiftTable
+func tableName(module string) string {
+	if module == "" {
+		return *swiftTable
+	}
+	return module
+}
+
+// pluralValue shifts the arguments of plurals that are passed the count as an additional first argument,
+// since %#@key@ always reads the count from the first argument. Main and flavor values are normalized like Normalize does,
+// but escaped for xml.
+func pluralValue(plural writer.QuantityString, value string) string {
+	other, ok := plural.Values[writer.QuantityOf("other")]
+	if ok && !writer.CountReplacesArgument(other.Value) {
+		value = writer.ShiftArguments(value, 1)
+	}
+	return plistEscaper.Replace(iosFormat(value))
+}
+
+// splitInfoPlist moves the strings of the InfoPlist group into their own model, using the plist keys (e.g. NSCameraUsageDescription) as keys.
+// InfoPlist.strings has no plurals, so any in the group are dropped.
+func splitInfoPlist(model *writer.LocalizationModel) (*writer.LocalizationModel, *writer.LocalizationModel) {
+	localizable := *model
+	localizable.Groups = make([]writer.Group, 0, len(model.Groups))
+	infoPlist := &writer.LocalizationModel{Headers: model.Headers, Plurals: &map[string]writer.QuantityString{}}
+
+	plurals := make(map[string]writer.QuantityString)
+	for key, plural := range *model.Plurals {
+		if writer.CompositeKeyOf(plural.Key).Group() != InfoPlistGroup {
+			plurals[key] = plural
+		}
+	}
+	localizable.Plurals = &plurals
+
+	for _, group := range model.Groups {
+		if group.Name != InfoPlistGroup {
+			localizable.Groups = append(localizable.Groups, group)
+			continue
+		}
+		plist := writer.Group{Name: group.Name}
+		for _, s := range group.Strings {
+			if writer.CompositeKeyOf(s.Key).Quantity() != "" {
+				continue
+			}
+			s.Key = writer.CompositeKeyOf(s.Key).Identifier()
+			plist.Strings = append(plist.Strings, s)
+		}
+		infoPlist.Groups = append(infoPlist.Groups, plist)
+	}
+	return &localizable, infoPlist
 }
 
 var androidStringFormat = regexp.MustCompile("%(\\d\\$)?s")
 
-func (writer IOSWriter) Normalize(s string) string {
-	var formatted = androidStringFormat.ReplaceAllStringFunc(s, func(s string) string {
+var stringsEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\"", "\\\"",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\t", "\\t",
+)
+
+// plistEscaper escapes the values of the .stringsdict, which is xml rather than a .strings file
+var plistEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+// Normalize converts Android format specifiers and escapes the string for .strings files, which also makes it a valid Swift literal.
+// Html values need no special treatment and are converted at runtime.
+func (writer IOSWriter) Normalize(s string, html bool) string {
+	return stringsEscaper.Replace(iosFormat(s))
+}
+
+// iosFormat replaces the string arguments of Android (%s) with the ones of iOS (%@)
+func iosFormat(s string) string {
+	return androidStringFormat.ReplaceAllStringFunc(s, func(s string) string {
 		return strings.Replace(s, "s", "@", 1)
 	})
-	return strings.Replace(formatted, "\"", "\\\"", -1)
 }
 
 func openFile(folder string, name string) *os.File {
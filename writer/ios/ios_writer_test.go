@@ -0,0 +1,95 @@
+package ios
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bleeding182/localization/writer"
+)
+
+func init() {
+	table, swift := "LocalizableGen", "Strings"
+	swiftTable, swiftType = &table, &swift
+}
+
+func plural(key, other string) (writer.QuantityString, writer.AndroidString) {
+	value := writer.AndroidString{Key: key + "__pl_other", Value: other}
+	return writer.QuantityString{
+		Key:    key,
+		Values: map[writer.Quantity]writer.LocalizedString{writer.QuantityOf("other"): {Key: writer.CompositeKeyOf(value.Key), Value: other}},
+	}, value
+}
+
+func TestSwiftPluralCount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []writer.Parameter
+	}{
+		{value: "%d bottles", want: []writer.Parameter{{Name: "count", Type: "Int"}}},
+		{value: "%1$d bottles of %2$@", want: []writer.Parameter{{Name: "count", Type: "Int"}, {Name: "p2", Type: "String"}}},
+		{value: "%@ has items", want: []writer.Parameter{{Name: "count", Type: "Int"}, {Name: "p1", Type: "String"}}},
+		{value: "items", want: []writer.Parameter{{Name: "count", Type: "Int"}}},
+	}
+	for _, test := range tests {
+		quantityString, value := plural("cart__items", test.value)
+		model := &writer.LocalizationModel{
+			Groups:  []writer.Group{{Name: "cart", Strings: []writer.AndroidString{value}}},
+			Plurals: &map[string]writer.QuantityString{"cart__items": quantityString},
+		}
+		plurals := newSwiftModel(model).Groups[0].Plurals
+		if len(plurals) != 1 || !reflect.DeepEqual(plurals[0].Parameters, test.want) {
+			t.Errorf("%q: got %+v, want parameters %v", test.value, plurals, test.want)
+		}
+	}
+}
+
+func TestPluralValue(t *testing.T) {
+	quantityString, _ := plural("cart__items", "%@ has %d items")
+	if got := pluralValue(quantityString, "%@ has one item"); got != "%2$@ has one item" {
+		t.Errorf("count was not inserted as the first argument: %q", got)
+	}
+	quantityString, _ = plural("cart__items", "%d items of %@")
+	if got := pluralValue(quantityString, "one item of %2$@"); got != "one item of %2$@" {
+		t.Errorf("count replacing the first argument must not shift: %q", got)
+	}
+	quantityString, _ = plural("cart__items", "%1$d items & %2$s")
+	if got := pluralValue(quantityString, "%1$d item & %2$s <b>"); got != "%1$d item &amp; %2$@ &lt;b&gt;" {
+		t.Errorf("values must be normalized for the stringsdict: %q", got)
+	}
+}
+
+func TestSplitInfoPlist(t *testing.T) {
+	localizedString := func(key, value string) writer.LocalizedString {
+		return writer.LocalizedString{Key: writer.CompositeKeyOf(key), Value: value}
+	}
+	model := &writer.LocalizationModel{
+		Groups: []writer.Group{
+			{Name: "greeting", Strings: []writer.AndroidString{{Key: "greeting_hello", Value: "Hello"}}},
+			{Name: "infoplist", Strings: []writer.AndroidString{
+				{Key: "infoplist__NSCameraUsageDescription", Value: "Camera"},
+				{Key: "infoplist__photos__pl_one", Value: "%d photo"},
+				{Key: "infoplist__photos__pl_other", Value: "%d photos"},
+			}},
+		},
+		Plurals: &map[string]writer.QuantityString{
+			"infoplist__photos": {Key: "infoplist__photos", Values: map[writer.Quantity]writer.LocalizedString{
+				writer.QuantityOf("one"):   localizedString("infoplist__photos__pl_one", "%d photo"),
+				writer.QuantityOf("other"): localizedString("infoplist__photos__pl_other", "%d photos"),
+			}},
+		},
+	}
+
+	localizable, infoPlist := splitInfoPlist(model)
+	if len(localizable.Groups) != 1 || len(*localizable.Plurals) != 0 {
+		t.Errorf("InfoPlist strings and plurals should be removed, got %v and %v", localizable.Groups, *localizable.Plurals)
+	}
+	if strings := infoPlist.Groups[0].Strings; len(strings) != 1 || strings[0].Key != "NSCameraUsageDescription" {
+		t.Errorf("InfoPlist.strings should only contain the plist key, got %v", strings)
+	}
+
+	// must not panic on the plurals of the InfoPlist group
+	swift := newSwiftModel(localizable)
+	if len(swift.Groups) != 1 || len(swift.Groups[0].Plurals) != 0 {
+		t.Errorf("Strings.swift should only contain the greeting, got %v", swift.Groups)
+	}
+}
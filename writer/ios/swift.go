@@ -0,0 +1,189 @@
+package ios
+
+import (
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/bleeding182/localization/writer"
+	"github.com/iancoleman/strcase"
+)
+
+const iosStringsUtilTemplate = `
+import Foundation
+#if canImport(UIKit)
+import UIKit
+#endif
+
+{{range $header := $.Headers -}}
+// {{$header}}
+{{end}}
+// swiftlint:disable line_length
+{{access}} struct {{typeName}} {
+
+    {{- range $g := $.Groups}}
+    {{access}} struct {{.Name}} {
+        {{- range $as := $g.Strings}}
+        {{- if .Parameters}}
+        {{access}} static func {{.Name}}({{template "parameters" .Parameters}}) -> String {
+            return String(format: {{template "localized" .}}, {{template "arguments" .Parameters}})
+        }
+        {{- if .HTML}}
+        {{access}} static func {{.Name}}Attributed({{template "parameters" .Parameters}}) -> NSAttributedString {
+            return {{typeName}}.attributed({{.Name}}({{template "arguments" .Parameters}}))
+        }
+        {{- end}}
+        {{- else}}
+        {{access}} static let {{.Name}} = {{template "localized" .}}
+        {{- if .HTML}}
+        {{access}} static var {{.Name}}Attributed: NSAttributedString { return {{typeName}}.attributed({{.Name}}) }
+        {{- end}}
+        {{- end}}
+        {{- end}}
+        {{- range $p := $g.Plurals}}
+        {{access}} static func {{.Name}}({{template "parameters" .Parameters}}) -> String {
+            return String.localizedStringWithFormat({{template "localized" .}}, {{template "arguments" .Parameters}})
+        }
+        {{- end}}
+    }
+    {{end}}
+
+    {{access}} static func localized(_ key: String, tableName: String? = nil, bundle: Bundle = {{bundle}}, value: String, comment: String = "") -> String {
+        return NSLocalizedString(key, tableName: tableName, bundle: bundle, value: value, comment: comment)
+    }
+
+    {{access}} static func attributed(_ html: String) -> NSAttributedString {
+        let options: [NSAttributedString.DocumentReadingOptionKey: Any] = [
+            .documentType: NSAttributedString.DocumentType.html,
+            .characterEncoding: String.Encoding.utf8.rawValue
+        ]
+        guard let data = html.data(using: .utf8),
+            let attributed = try? NSAttributedString(data: data, options: options, documentAttributes: nil) else {
+            return NSAttributedString(string: html)
+        }
+        return attributed
+    }
+}
+{{- define "localized"}}{{typeName}}.localized("{{.Key}}", tableName: "{{.Table}}", value: "{{.Value}}"{{if .Comment}}, comment: "{{.Comment}}"{{end}}){{end}}
+{{- define "parameters"}}{{range $i, $p := .}}{{if $i}}, {{end}}_ {{.Name}}: {{.Type}}{{end}}{{end}}
+{{- define "arguments"}}{{range $i, $p := .}}{{if $i}}, {{end}}{{.Name}}{{end}}{{end}}
+`
+
+var swiftFuncs = template.FuncMap{
+	"access":   func() string { return *swiftAccess },
+	"typeName": func() string { return *swiftType },
+	"bundle":   swiftBundleExpression,
+}
+
+// swiftBundleExpression maps the --swiftBundle shortcuts `main` and `module` to their Bundle, any other value is used as-is
+func swiftBundleExpression() string {
+	switch *swiftBundle {
+	case "main":
+		return "Bundle.main"
+	case "module":
+		return "Bundle.module"
+	}
+	return *swiftBundle
+}
+
+var swiftTypes = map[writer.ArgumentType]string{
+	writer.IntArgument:    "Int",
+	writer.DoubleArgument: "Double",
+	writer.StringArgument: "String",
+}
+
+type swiftModel struct {
+	Headers *[]string
+	Groups  []swiftGroup
+}
+
+type swiftGroup struct {
+	Name    string
+	Strings []swiftString
+	Plurals []swiftString
+}
+
+// swiftString is a static constant, or a function if it has any parameters
+type swiftString struct {
+	writer.AndroidString
+	Name       string
+	Table      string
+	Parameters []writer.Parameter
+}
+
+// newSwiftModel groups the strings for Strings.swift. Plurals replace their quantity strings with a single function taking the count,
+// which is either their first int argument or an additional one, see pluralValue.
+func newSwiftModel(model *writer.LocalizationModel) swiftModel {
+	plurals := make(map[string][]swiftString)
+	for _, plural := range *model.Plurals {
+		other, ok := plural.Values[writer.QuantityOf("other")]
+		if !ok {
+			continue
+		}
+		value, ok := writer.FindString(model, other.Key.Original())
+		if !ok {
+			continue
+		}
+		value.Key = plural.Key
+
+		s := newSwiftString(value)
+		s.Parameters = writer.PluralParameters(value.Value, swiftTypes)
+
+		group := other.Key.Group()
+		plurals[group] = append(plurals[group], s)
+	}
+
+	swift := swiftModel{Headers: model.Headers}
+	for _, g := range model.Groups {
+		group := swiftGroup{
+			Name:    strcase.ToCamel(g.Name),
+			Plurals: plurals[g.Name],
+		}
+		sort.Slice(group.Plurals, func(i, j int) bool {
+			return group.Plurals[i].Key < group.Plurals[j].Key
+		})
+		for _, s := range g.Strings {
+			if writer.CompositeKeyOf(s.Key).Quantity() != "" {
+				continue
+			}
+			group.Strings = append(group.Strings, newSwiftString(s))
+		}
+		swift.Groups = append(swift.Groups, group)
+	}
+	return swift
+}
+
+func newSwiftString(s writer.AndroidString) swiftString {
+	return swiftString{
+		AndroidString: s,
+		Name:          strcase.ToCamel(s.Key),
+		Table:         tableName(s.Module),
+		Parameters:    writer.Parameters(s.Value, swiftTypes),
+	}
+}
+
+// Identifiers of the group struct and the member generated in Strings.swift, strings of the InfoPlist group are not part of it
+func (w IOSWriter) Identifiers(key writer.CompositeKey) []writer.Identifier {
+	if key.Group() == InfoPlistGroup {
+		return nil
+	}
+	group := *swiftType + "." + strcase.ToCamel(key.Group())
+	return []writer.Identifier{
+		{Name: group, Source: writer.GroupSource(key)},
+		{Name: group + "." + strcase.ToCamel(key.PlainKey()), Source: writer.KeySource(key)},
+	}
+}
+
+// ValidateKey checks that the group and key generate valid Swift names. The InfoPlist group generates no code, but can't contain plurals.
+func (w IOSWriter) ValidateKey(key writer.CompositeKey) error {
+	if key.Group() == InfoPlistGroup {
+		if key.Quantity() != "" {
+			return fmt.Errorf("%q is a plural, but InfoPlist.strings doesn't support plurals", key.Original())
+		}
+		return nil
+	}
+	if err := writer.CheckIdentifier("Swift", strcase.ToCamel(key.Group()), writer.SwiftKeywords); err != nil {
+		return err
+	}
+	return writer.CheckIdentifier("Swift", strcase.ToCamel(key.PlainKey()), writer.SwiftKeywords)
+}
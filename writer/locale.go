@@ -0,0 +1,115 @@
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the name of the sheet containing the base language.
+const DefaultLocale = "default"
+
+// Locale of a sheet, parsed from its title as a BCP-47 tag, e.g. "de", "pt-BR" or "zh-Hant-TW"
+type Locale struct {
+	Name     string // title of the sheet
+	Language string // e.g. "zh"
+	Script   string // optional, e.g. "Hant"
+	Region   string // optional, e.g. "TW"
+}
+
+// ParseLocale parses the tag as a BCP-47 language tag. `default` is accepted for the base language.
+// Deprecated codes are kept as they are, e.g. "iw" stays "iw" instead of becoming "he", since Android apps still use them for their folders.
+func ParseLocale(name string, tag string) (Locale, error) {
+	if tag == DefaultLocale {
+		return Locale{Name: name}, nil
+	}
+
+	t, err := language.Raw.Parse(tag)
+	if err != nil {
+		return Locale{}, fmt.Errorf("sheet %q is not a valid BCP-47 locale (%q): %v", name, tag, err)
+	}
+
+	base, script, region := t.Raw()
+	locale := Locale{Name: name, Language: base.String()}
+	if s := script.String(); s != "Zzzz" {
+		locale.Script = s
+	}
+	if r := region.String(); r != "ZZ" {
+		locale.Region = r
+	}
+	if locale.String() != t.String() {
+		return Locale{}, fmt.Errorf("sheet %q uses unsupported variants or extensions (%q), only language, script and region are supported", name, t)
+	}
+	return locale, nil
+}
+
+// IsDefault returns true for the base language
+func (locale Locale) IsDefault() bool {
+	return locale.Language == ""
+}
+
+// String returns the canonical BCP-47 tag, e.g. "zh-Hant-TW", or "default" for the base language
+func (locale Locale) String() string {
+	if locale.IsDefault() {
+		return DefaultLocale
+	}
+	return strings.Join(locale.parts(), "-")
+}
+
+// Qualifier returns the Android resource qualifier, e.g. "de", "pt-rBR" or "b+sr+Latn".
+// Qualifiers including a script or a numeric region (e.g. "es-419") have to use the BCP-47 syntax introduced with Android 7.0
+func (locale Locale) Qualifier() string {
+	if qualifier, err := locale.LegacyQualifier(); err == nil {
+		return qualifier
+	}
+	return "b+" + strings.Join(locale.parts(), "+")
+}
+
+// LegacyQualifier returns the resource qualifier in the "de" or "pt-rBR" format, which can't represent a script or a numeric region
+func (locale Locale) LegacyQualifier() (string, error) {
+	if locale.Script != "" || strings.IndexAny(locale.Region, "0123456789") >= 0 {
+		return "", fmt.Errorf("locale %v of sheet %q needs a language and an optional two letter region only", locale, locale.Name)
+	}
+	if locale.Region != "" {
+		return locale.Language + "-r" + locale.Region, nil
+	}
+	return locale.Language, nil
+}
+
+// ParseQualifier parses the locale of an Android resource qualifier, e.g. "de", "pt-rBR" or "b+sr+Latn". An empty qualifier is the default locale.
+func ParseQualifier(qualifier string) (Locale, error) {
+	if qualifier == "" {
+		return ParseLocale(DefaultLocale, DefaultLocale)
+	}
+	var tag string
+	if strings.HasPrefix(qualifier, "b+") {
+		tag = strings.Replace(qualifier[2:], "+", "-", -1)
+	} else {
+		parts := strings.Split(qualifier, "-")
+		if len(parts) > 2 || len(parts[0]) > 3 || (len(parts) == 2 && (len(parts[1]) != 3 || parts[1][0] != 'r')) {
+			return Locale{}, fmt.Errorf("qualifier %q is not a locale", qualifier)
+		}
+		tag = parts[0]
+		if len(parts) == 2 {
+			tag += "-" + parts[1][1:]
+		}
+	}
+	locale, err := ParseLocale(tag, tag)
+	if err != nil {
+		return Locale{}, fmt.Errorf("qualifier %q is not a locale: %v", qualifier, err)
+	}
+	locale.Name = locale.String()
+	return locale, nil
+}
+
+func (locale Locale) parts() []string {
+	parts := []string{locale.Language}
+	if locale.Script != "" {
+		parts = append(parts, locale.Script)
+	}
+	if locale.Region != "" {
+		parts = append(parts, locale.Region)
+	}
+	return parts
+}
@@ -0,0 +1,55 @@
+package writer
+
+import "testing"
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		tag, name, qualifier string // name is also the iOS folder, e.g. pt-BR.lproj
+	}{
+		{"default", "default", ""},
+		{"de", "de", "de"},
+		{"pt-BR", "pt-BR", "pt-rBR"},
+		{"pt-br", "pt-BR", "pt-rBR"},
+		{"sr-Latn", "sr-Latn", "b+sr+Latn"},
+		{"zh-Hant", "zh-Hant", "b+zh+Hant"},
+		{"zh-Hant-TW", "zh-Hant-TW", "b+zh+Hant+TW"},
+		{"es-419", "es-419", "b+es+419"},
+		{"iw", "iw", "iw"},
+		{"in", "in", "in"},
+		{"tl", "tl", "tl"},
+	}
+	for _, test := range tests {
+		locale, err := ParseLocale(test.tag, test.tag)
+		if err != nil {
+			t.Errorf("ParseLocale(%q) failed: %v", test.tag, err)
+			continue
+		}
+		if got := locale.String(); got != test.name {
+			t.Errorf("ParseLocale(%q).String() = %q, want %q", test.tag, got, test.name)
+		}
+		if locale.IsDefault() {
+			continue
+		}
+		if got := locale.Qualifier(); got != test.qualifier {
+			t.Errorf("ParseLocale(%q).Qualifier() = %q, want %q", test.tag, got, test.qualifier)
+		}
+	}
+}
+
+func TestParseLocaleInvalid(t *testing.T) {
+	for _, tag := range []string{"", "Sheet1", "de-DE-1996", "en-u-ca-gregory"} {
+		if _, err := ParseLocale(tag, tag); err == nil {
+			t.Errorf("ParseLocale(%q) should fail", tag)
+		}
+	}
+}
+
+func TestLegacyQualifier(t *testing.T) {
+	for tag, want := range map[string]string{"de": "de", "pt-BR": "pt-rBR", "sr-Latn": "", "es-419": ""} {
+		locale, _ := ParseLocale(tag, tag)
+		got, err := locale.LegacyQualifier()
+		if (err != nil) != (want == "") || got != want {
+			t.Errorf("LegacyQualifier(%q) = %q, %v, want %q", tag, got, err, want)
+		}
+	}
+}
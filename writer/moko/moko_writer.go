@@ -0,0 +1,195 @@
+package moko
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/bleeding182/localization/writer"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const mokoStringsTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+{{range $header := $.Headers -}}
+<!-- {{$header}} -->
+{{end -}}
+<resources>
+{{- range $g := $.Groups}}
+    <!-- region {{.Name}} -->
+    {{- range $as := $g.Strings}}
+    {{- if .Comment}}
+    <!-- {{.Comment}} -->
+    {{- end}}
+    <string name="{{resourceName .Key}}">{{.Value}}</string>
+    {{- end}}
+    <!-- endregion -->
+{{end}}
+</resources>
+`
+
+const mokoPluralsTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+{{range $header := $.Headers -}}
+<!-- {{$header}} -->
+{{end -}}
+<resources>
+{{- range $p := $.Plurals}}
+    <plural name="{{.Key}}">
+    {{- range $q := .Quantities}}
+        <item quantity="{{.Quantity}}">{{.Value}}</item>
+    {{- end}}
+    </plural>
+{{- end}}
+</resources>
+`
+
+const tagMoko = "moko"
+
+var outputFolder *string
+
+func (writer MokoWriter) RegisterCommand(app *kingpin.Application) {
+	command := app.Command(tagMoko, "Export your strings for moko-resources. This will generate strings.xml and plurals.xml in MR/base and MR/<locale> folders.")
+	outputFolder = command.Flag("outputFolder", "Set the resources directory of your shared module where the MR folder will be generated. Modules will be generated in subdirectories.").Default("exports").String()
+}
+
+// MokoWriter exports strings and plurals for moko-resources in Kotlin Multiplatform projects
+type MokoWriter struct{}
+
+func (writer MokoWriter) Tag() string {
+	return tagMoko
+}
+
+type mokoModel struct {
+	Headers *[]string
+	Groups  []writer.Group
+	Plurals []mokoPlural
+}
+
+type mokoPlural struct {
+	Key        string
+	Quantities []mokoQuantity
+}
+
+type mokoQuantity struct {
+	Quantity writer.Quantity
+	Value    string
+}
+
+func (writer MokoWriter) Export(locale writer.Locale, model *writer.LocalizationModel) {
+	var folder string
+	if locale.IsDefault() {
+		folder = "base"
+	} else {
+		qualifier, err := locale.LegacyQualifier()
+		check(err)
+		folder = qualifier
+	}
+
+	stringsTemplate, err := template.New("strings").Funcs(funcs).Parse(mokoStringsTemplate)
+	check(err)
+	pluralsTemplate, err := template.New("plurals").Parse(mokoPluralsTemplate)
+	check(err)
+
+	for _, module := range model.Modules() {
+		localeFolder := path.Join(*outputFolder, module, "MR", folder)
+		moko := newMokoModel(model.Module(module))
+
+		stringsFile := openFile(localeFolder, "strings.xml")
+		err = stringsTemplate.Execute(stringsFile, moko)
+		check(err)
+		check(stringsFile.Close())
+
+		pluralsFile := openFile(localeFolder, "plurals.xml")
+		err = pluralsTemplate.Execute(pluralsFile, moko)
+		check(err)
+		check(pluralsFile.Close())
+	}
+}
+
+var funcs = template.FuncMap{
+	"resourceName": writer.ResourceName,
+}
+
+// newMokoModel moves the quantity strings from strings.xml into their plurals
+func newMokoModel(model *writer.LocalizationModel) mokoModel {
+	values := make(map[string]string)
+	moko := mokoModel{Headers: model.Headers}
+	for _, g := range model.Groups {
+		group := writer.Group{Name: g.Name}
+		for _, s := range g.Strings {
+			values[s.Key] = s.Value
+			if writer.CompositeKeyOf(s.Key).Quantity() == "" {
+				group.Strings = append(group.Strings, s)
+			}
+		}
+		if len(group.Strings) > 0 {
+			moko.Groups = append(moko.Groups, group)
+		}
+	}
+
+	for _, plural := range *model.Plurals {
+		p := mokoPlural{Key: writer.ResourceName(plural.Key)}
+		for quantity, s := range plural.Values {
+			p.Quantities = append(p.Quantities, mokoQuantity{quantity, values[s.Key.Original()]})
+		}
+		sort.Slice(p.Quantities, func(i, j int) bool {
+			return p.Quantities[i].Quantity < p.Quantities[j].Quantity
+		})
+		moko.Plurals = append(moko.Plurals, p)
+	}
+	sort.Slice(moko.Plurals, func(i, j int) bool {
+		return moko.Plurals[i].Key < moko.Plurals[j].Key
+	})
+	return moko
+}
+
+// Identifiers of the generated MR.strings.* and MR.plurals.* accessors
+func (w MokoWriter) Identifiers(key writer.CompositeKey) []writer.Identifier {
+	if key.Quantity() != "" {
+		return []writer.Identifier{{Name: "MR.plurals." + writer.ResourceName(key.PlainKey()), Source: writer.KeySource(key)}}
+	}
+	return []writer.Identifier{{Name: "MR.strings." + writer.ResourceName(key.Original()), Source: writer.KeySource(key)}}
+}
+
+// ValidateKey checks that the accessor isn't a reserved word in Kotlin
+func (w MokoWriter) ValidateKey(key writer.CompositeKey) error {
+	return writer.CheckIdentifier("Kotlin", writer.ResourceName(key.PlainKey()), writer.KotlinKeywords)
+}
+
+var iosStringFormat = regexp.MustCompile("%(\\d\\$)?@")
+
+var xmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\n", "\\n",
+)
+
+// Normalize converts iOS format specifiers and escapes the string for the xml file.
+// moko-resources escapes the values for each platform, so html will be treated as text.
+func (writer MokoWriter) Normalize(s string, html bool) string {
+	s = iosStringFormat.ReplaceAllStringFunc(s, func(s string) string {
+		return strings.Replace(s, "@", "s", 1)
+	})
+	return xmlEscaper.Replace(s)
+}
+
+func openFile(folder string, name string) *os.File {
+	foldername := fmt.Sprintf("%v", folder)
+	os.MkdirAll(foldername, os.ModePerm)
+
+	filename := fmt.Sprintf("%v/%v", foldername, name)
+	f, err := os.Create(filename)
+	check(err)
+	return f
+}
+
+func check(e error) {
+	if e != nil {
+		panic(e)
+	}
+}
@@ -11,6 +11,19 @@ type QuantityString struct {
 	Values map[Quantity]LocalizedString
 }
 
+// Module of the plural, taken from its "other" quantity or, if it has none, its lowest one
+func (plural QuantityString) Module() string {
+	if s, ok := plural.Values[other]; ok {
+		return s.Module
+	}
+	for quantity := zero; quantity < other; quantity++ {
+		if s, ok := plural.Values[quantity]; ok {
+			return s.Module
+		}
+	}
+	return ""
+}
+
 // https://developer.android.com/guide/topics/resources/string-resource.html#Plurals
 const (
 	zero  Quantity = 0 // When the language requires special treatment of the number 0 (as in Arabic).
@@ -30,6 +43,16 @@ var quantities = map[Quantity]string{
 	other: "other",
 }
 
+// IsQuantity returns true for the names of the quantities, e.g. "one" or "other"
+func IsQuantity(quantityString string) bool {
+	for _, s := range quantities {
+		if s == quantityString {
+			return true
+		}
+	}
+	return false
+}
+
 // QuantityOf the quantityString mapped to Quantity, e.g. "zero" -> 0
 func QuantityOf(quantityString string) Quantity {
 	for q, s := range quantities {
@@ -1,19 +1,34 @@
 package writer
 
 import (
+	"sort"
+	"strings"
+
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 )
 
 type Writer interface {
 	Tag() string
-	Export(locale string, model *LocalizationModel)
+	Export(locale Locale, model *LocalizationModel)
 
-	// Convert between %s and %@ and possible other differences between ios/android
-	Normalize(string) string
+	// Convert between %s and %@ and possible other differences between ios/android.
+	// Normalize is responsible for all escaping required by the target platform, html values must keep their markup.
+	Normalize(s string, html bool) string
 
 	RegisterCommand(app *kingpin.Application)
 }
 
+// Finisher can optionally be implemented by a Writer to write additional files once all locales were exported.
+type Finisher interface {
+	Finish(locales []Locale)
+}
+
+// FlavorExporter can optionally be implemented by a Writer to export product flavors, e.g. for white-label apps.
+// The model of a flavor contains all strings, with Flavored set for the ones overridden in a `<column>@<flavor>` column.
+type FlavorExporter interface {
+	ExportFlavor(flavor string, locale Locale, model *LocalizationModel)
+}
+
 type LocalizationModel struct {
 	Headers *[]string
 	Groups  []Group
@@ -29,10 +44,85 @@ type AndroidString struct {
 	Key     string
 	Value   string
 	Comment string
+	HTML    bool   // Value contains markup, e.g. <b> or <a href>
+	Module  string // optional module or table the string should be exported to
+
+	Translatable bool // false for strings like urls or brand names that only exist in the default locale
+	Flavored     bool // the value was overridden for the flavor of the model
 }
 
 type LocalizedString struct {
+	Row            int // row in the sheet, starting at 1
 	Key            CompositeKey
 	Value, Comment string
+	HTML           bool
+	Translatable   bool
+	Module         string
+	Platforms      []string // e.g. [android, web] or [!ios]
+	MaxLength      int      // maximum length of the rendered value, 0 if there is none
+	Notes          []string // notes of the cells in the row, only read with --gridData
+	Excluded       bool     // the row was marked as excluded by its formatting, e.g. with strikethrough
 	Entries        []string
 }
+
+// ExportsTo returns false if the platform is excluded with `!tag` or the string is limited to other platforms
+func (s LocalizedString) ExportsTo(tag string) bool {
+	included, limited := false, false
+	for _, platform := range s.Platforms {
+		if strings.HasPrefix(platform, "!") {
+			if platform[1:] == tag {
+				return false
+			}
+			continue
+		}
+		limited = true
+		if platform == tag {
+			included = true
+		}
+	}
+	return included || !limited
+}
+
+// Modules returns all modules used by the model, "" being the default module
+func (model *LocalizationModel) Modules() []string {
+	seen := map[string]bool{"": true}
+	modules := []string{""}
+	for _, group := range model.Groups {
+		for _, s := range group.Strings {
+			if !seen[s.Module] {
+				seen[s.Module] = true
+				modules = append(modules, s.Module)
+			}
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// Module returns a copy of the model that only contains the strings and plurals of the given module
+func (model *LocalizationModel) Module(module string) *LocalizationModel {
+	filtered := &LocalizationModel{
+		Headers: model.Headers,
+		Groups:  make([]Group, 0, len(model.Groups)),
+	}
+	for _, group := range model.Groups {
+		g := Group{Name: group.Name}
+		for _, s := range group.Strings {
+			if s.Module == module {
+				g.Strings = append(g.Strings, s)
+			}
+		}
+		if len(g.Strings) > 0 {
+			filtered.Groups = append(filtered.Groups, g)
+		}
+	}
+
+	plurals := make(map[string]QuantityString)
+	for key, plural := range *model.Plurals {
+		if plural.Module() == module {
+			plurals[key] = plural
+		}
+	}
+	filtered.Plurals = &plurals
+	return filtered
+}
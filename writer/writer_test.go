@@ -0,0 +1,39 @@
+package writer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestModules(t *testing.T) {
+	other := LocalizedString{Key: CompositeKeyOf("checkout_items__pl_other"), Module: "Checkout"}
+	model := &LocalizationModel{
+		Groups: []Group{
+			{Name: "greeting", Strings: []AndroidString{{Key: "greeting_hello"}}},
+			{Name: "checkout", Strings: []AndroidString{{Key: "checkout_pay", Module: "Checkout"}, {Key: "checkout_items__pl_other", Module: "Checkout"}}},
+		},
+		Plurals: &map[string]QuantityString{"checkout_items": {Key: "checkout_items", Values: map[Quantity]LocalizedString{QuantityOf("other"): other}}},
+	}
+	if got := model.Modules(); !reflect.DeepEqual(got, []string{"", "Checkout"}) {
+		t.Errorf("Modules() = %v", got)
+	}
+	if checkout := model.Module("Checkout"); len(checkout.Groups) != 1 || len(checkout.Groups[0].Strings) != 2 || len(*checkout.Plurals) != 1 {
+		t.Errorf("Module(Checkout) = %+v", checkout)
+	}
+	if base := model.Module(""); len(base.Groups) != 1 || base.Groups[0].Name != "greeting" || len(*base.Plurals) != 0 {
+		t.Errorf("Module(\"\") = %+v", base)
+	}
+}
+
+func TestQuantityStringModule(t *testing.T) {
+	plural := QuantityString{Values: map[Quantity]LocalizedString{one: {Module: "A"}, few: {Module: "B"}, other: {Module: "C"}}}
+	for i := 0; i < 10; i++ {
+		if got := plural.Module(); got != "C" {
+			t.Fatalf("Module() = %q, want the module of other", got)
+		}
+	}
+	delete(plural.Values, other)
+	if got := plural.Module(); got != "A" {
+		t.Errorf("Module() = %q, want the module of the lowest quantity", got)
+	}
+}